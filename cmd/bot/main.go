@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 	"github.com/melophe/Discord-ENG/internal/bot"
@@ -20,16 +21,18 @@ func main() {
 	// Load configuration from environment variables
 	cfg := config.Load()
 
+	// Database maintenance does not need Discord or model settings
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg.Database.Path, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Validate required config
 	if cfg.Discord.Token == "" {
 		log.Fatal("DISCORD_TOKEN is required")
 	}
-	if cfg.Discord.ChannelID == "" {
-		log.Fatal("DISCORD_CHANNEL_ID is required")
-	}
-	if cfg.Claude.APIKey == "" {
-		log.Fatal("CLAUDE_API_KEY is required")
-	}
 
 	// Initialize database
 	database, err := db.New(cfg.Database.Path)
@@ -38,11 +41,25 @@ func main() {
 	}
 	defer database.Close()
 
-	// Initialize Claude client
-	claudeClient := claude.NewClient(cfg.Claude.APIKey, cfg.Claude.Model)
+	// Initialize the quiz model
+	var model claude.QuizModel
+	switch cfg.LLM.Provider {
+	case "claude":
+		if cfg.Claude.APIKey == "" {
+			log.Fatal("CLAUDE_API_KEY is required")
+		}
+		model = claude.NewClient(cfg.Claude.APIKey, cfg.Claude.Model)
+	case "openai":
+		model = claude.NewOpenAIClient(cfg.OpenAI.BaseURL, cfg.OpenAI.APIKey, cfg.OpenAI.Model)
+	case "fake":
+		log.Println("Using the fake quiz model; questions and scores are not real")
+		model = claude.NewFake()
+	default:
+		log.Fatalf("Unknown LLM_PROVIDER %q (expected claude, openai or fake)", cfg.LLM.Provider)
+	}
 
 	// Initialize bot
-	discordBot, err := bot.New(cfg, database, claudeClient)
+	discordBot, err := bot.New(cfg, database, model)
 	if err != nil {
 		log.Fatalf("Failed to create bot: %v", err)
 	}
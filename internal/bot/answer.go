@@ -0,0 +1,188 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// answerModalPrefix starts the custom ID of answer modals, followed by the ID
+// of the quiz message being answered
+const answerModalPrefix = "answer_modal_submit:"
+
+// retryPrefix starts the custom ID of retry buttons on evaluations, followed by
+// the ID of the quiz message to answer again
+const retryPrefix = "answer_retry:"
+
+// createAnswerButton creates the button that opens the answer modal
+func createAnswerButton() discordgo.Button {
+	return discordgo.Button{
+		Label:    "✍️ 回答する",
+		Style:    discordgo.SuccessButton,
+		CustomID: "answer_open",
+	}
+}
+
+// createEvaluationButtons creates the buttons shown under an evaluation of an
+// answer to the quiz message
+func createEvaluationButtons(quizMessageID string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "🔁 もう一度",
+					Style:    discordgo.PrimaryButton,
+					CustomID: retryPrefix + quizMessageID,
+				},
+				createVocabSaveButton(quizMessageID),
+			},
+		},
+	}
+}
+
+// quizForClick looks up the quiz on the clicked message, responding with a
+// warning and returning nil if the user may not use it
+func (b *Bot) quizForClick(i *discordgo.InteractionCreate) *db.QuizMessage {
+	if i.Message == nil {
+		b.respondComponentMessage(i, "エラーが発生しました")
+		return nil
+	}
+	return b.quizForMessage(i, i.Message.ID)
+}
+
+// quizForMessage looks up the quiz on a message, responding with a warning
+// and returning nil if the user may not use it
+func (b *Bot) quizForMessage(i *discordgo.InteractionCreate, messageID string) *db.QuizMessage {
+	quiz, err := b.db.GetQuizMessage(messageID)
+	if err != nil || quiz == nil {
+		b.respondComponentMessage(i, "❌ この問題は見つかりませんでした")
+		return nil
+	}
+
+	if quiz.DiscordID != "" && quiz.DiscordID != interactionUserID(i) {
+		b.respondComponentMessage(i, "⚠️ この問題は他のユーザー宛てです。/quiz で自分の問題を出題できます")
+		return nil
+	}
+	return quiz
+}
+
+// handleAnswerButton opens a modal for answering the quiz on the clicked message
+func (b *Bot) handleAnswerButton(i *discordgo.InteractionCreate) {
+	if quiz := b.quizForClick(i); quiz != nil {
+		b.openAnswerModal(i, quiz)
+	}
+}
+
+// handleRetryButton opens the answer modal again for the quiz an evaluation
+// belongs to
+func (b *Bot) handleRetryButton(i *discordgo.InteractionCreate, quizMessageID string) {
+	if quiz := b.quizForMessage(i, quizMessageID); quiz != nil {
+		b.openAnswerModal(i, quiz)
+	}
+}
+
+// openAnswerModal responds with a modal for answering the quiz
+func (b *Bot) openAnswerModal(i *discordgo.InteractionCreate, quiz *db.QuizMessage) {
+	question, err := b.db.GetQuestion(quiz.QuestionID)
+	if err != nil {
+		b.respondComponentMessage(i, "❌ この問題は見つかりませんでした")
+		return
+	}
+
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: answerModalPrefix + quiz.MessageID,
+			Title:    fmt.Sprintf("✍️ %s #%d", questionKind(question), question.ID),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "answer_input",
+							Label:       questionAnswerLabel(question),
+							Style:       discordgo.TextInputParagraph,
+							Placeholder: truncate(question.Japanese, 100),
+							Required:    true,
+							MaxLength:   1000,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handleAnswerModalSubmit evaluates an answer submitted through the modal and
+// shows the result only to the user who answered
+func (b *Bot) handleAnswerModalSubmit(i *discordgo.InteractionCreate, messageID string) {
+	answer := strings.TrimSpace(modalValue(i, "answer_input"))
+	if answer == "" {
+		b.respondComponentMessage(i, "❌ 回答を入力してください")
+		return
+	}
+
+	quiz, err := b.db.GetQuizMessage(messageID)
+	if err != nil || quiz == nil {
+		b.respondComponentMessage(i, "❌ この問題は見つかりませんでした")
+		return
+	}
+
+	userID := interactionUserID(i)
+	if quiz.DiscordID != "" && quiz.DiscordID != userID {
+		b.respondComponentMessage(i, "⚠️ この問題は他のユーザー宛てです。/quiz で自分の問題を出題できます")
+		return
+	}
+
+	// Challenge answers are collected and evaluated together when it closes
+	challenge, err := b.db.GetChallenge(messageID)
+	if err != nil {
+		log.Printf("Error getting challenge: %v", err)
+		b.respondComponentMessage(i, "エラーが発生しました")
+		return
+	}
+	if challenge != nil {
+		accepted, err := b.acceptChallengeEntry(challenge, userID, answer)
+		switch {
+		case err != nil:
+			log.Printf("Error saving challenge entry: %v", err)
+			b.respondComponentMessage(i, "❌ 回答の受付に失敗しました")
+		case !accepted:
+			b.respondComponentMessage(i, "⏱️ このチャレンジは締め切られました")
+		default:
+			b.respondComponentMessage(i, "✅ 回答を受け付けました！締切後に結果を発表します")
+		}
+		return
+	}
+
+	b.respondWithEvaluation(i, quiz, answer)
+}
+
+// respondWithEvaluation evaluates an answer given through an interaction and
+// shows the result only to the user who answered
+func (b *Bot) respondWithEvaluation(i *discordgo.InteractionCreate, quiz *db.QuizMessage, answer string) {
+	userID := interactionUserID(i)
+	b.deferResponse(i, true)
+
+	eval, err := b.evaluateAnswer(quiz, userID, answer)
+	if err != nil {
+		log.Printf("Error evaluating answer: %v", err)
+		b.respondError(i, "❌ 回答の評価に失敗しました")
+		return
+	}
+
+	embed := b.createEvaluationEmbed(answer, eval)
+	components := createEvaluationButtons(quiz.MessageID)
+	if _, err := b.session.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	}); err != nil {
+		log.Printf("Error sending evaluation: %v", err)
+	}
+
+	if eval.recorded {
+		b.announceStreakMilestone(i.ChannelID, i.GuildID, userID)
+	}
+}
@@ -0,0 +1,177 @@
+package bot
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// clickOn builds a button click on the given message
+func clickOn(message *discordgo.Message, customID string) *discordgo.InteractionCreate {
+	i := componentClick(customID)
+	i.Message = message
+	return i
+}
+
+func TestAnswerModal(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	quiz := session.lastMessage()
+
+	b.onInteractionCreate(nil, clickOn(quiz, "answer_open"))
+
+	resp := session.lastResponse()
+	if resp.Type != discordgo.InteractionResponseModal {
+		t.Fatalf("Expected a modal, got %+v", resp)
+	}
+	if resp.Data.CustomID != answerModalPrefix+quiz.ID {
+		t.Errorf("Expected modal for message %s, got %q", quiz.ID, resp.Data.CustomID)
+	}
+	input := resp.Data.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.TextInput)
+	if input.Style != discordgo.TextInputParagraph || input.Placeholder != "私は毎朝コーヒーを飲みます。" {
+		t.Errorf("Expected paragraph input showing the question, got %+v", input)
+	}
+
+	b.onInteractionCreate(nil, modalSubmit(resp.Data.CustomID, map[string]string{
+		"answer_input": "I drink coffee every morning.",
+	}))
+
+	resp = session.lastResponse()
+	if resp.Data == nil || resp.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Errorf("Expected an ephemeral response, got %+v", resp)
+	}
+	edit := session.lastEdit()
+	if edit == nil || edit.Embeds == nil || !strings.Contains((*edit.Embeds)[0].Title, "回答評価") {
+		t.Fatalf("Expected evaluation in the ephemeral response, got %+v", edit)
+	}
+	if len(session.sent) != 0 {
+		t.Errorf("Expected nothing posted to the channel, got %+v", session.sent)
+	}
+
+	stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+	if stats.TotalAnswers != 1 || stats.HighestScore != 100 {
+		t.Errorf("Expected the answer to be saved, got %+v", stats)
+	}
+}
+
+func TestAnswerModal_OtherUser(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	quiz := session.lastMessage()
+
+	click := clickOn(quiz, "answer_open")
+	click.Member = testMember("someone-else")
+	b.onInteractionCreate(nil, click)
+
+	resp := session.lastResponse()
+	if resp.Type == discordgo.InteractionResponseModal || !strings.Contains(resp.Data.Content, "他のユーザー宛て") {
+		t.Errorf("Expected a warning instead of a modal, got %+v", resp)
+	}
+}
+
+func TestAnswerModal_Challenge(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("challenge"))
+	post := session.lastMessage()
+
+	b.onInteractionCreate(nil, modalSubmit(answerModalPrefix+post.ID, map[string]string{
+		"answer_input": "I drink coffee every morning.",
+	}))
+
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "回答を受け付けました") {
+		t.Errorf("Expected entry confirmation, got %+v", resp.Data)
+	}
+	entries, _ := b.db.GetChallengeEntries(post.ID)
+	if len(entries) != 1 || entries[0].DiscordID != testUserID {
+		t.Errorf("Expected the modal answer as a challenge entry, got %+v", entries)
+	}
+}
+
+func TestRetryButton(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	quiz := session.lastMessage()
+
+	b.onInteractionCreate(nil, modalSubmit(answerModalPrefix+quiz.ID, map[string]string{
+		"answer_input": "I drink coffee.",
+	}))
+
+	edit := session.lastEdit()
+	if edit.Components == nil {
+		t.Fatalf("Expected buttons on the evaluation, got %+v", edit)
+	}
+	retry := (*edit.Components)[0].(discordgo.ActionsRow).Components[0].(discordgo.Button)
+	if retry.CustomID != retryPrefix+quiz.ID {
+		t.Fatalf("Expected a retry button for the quiz, got %+v", retry)
+	}
+
+	b.onInteractionCreate(nil, componentClick(retry.CustomID))
+	resp := session.lastResponse()
+	if resp.Type != discordgo.InteractionResponseModal || resp.Data.CustomID != answerModalPrefix+quiz.ID {
+		t.Fatalf("Expected the answer modal again, got %+v", resp)
+	}
+
+	b.onInteractionCreate(nil, modalSubmit(resp.Data.CustomID, map[string]string{
+		"answer_input": "I drink coffee every morning.",
+	}))
+
+	embed := (*session.lastEdit().Embeds)[0]
+	var attempt *discordgo.MessageEmbedField
+	for _, f := range embed.Fields {
+		if strings.Contains(f.Name, "2回目") {
+			attempt = f
+		}
+	}
+	if attempt == nil || !strings.Contains(attempt.Value, "+") {
+		t.Errorf("Expected the score delta for the second attempt, got %+v", embed.Fields)
+	}
+
+	// The first evaluation showed the model answer, so the retry is practice
+	if embed.Footer == nil || !strings.Contains(embed.Footer.Text, "練習") {
+		t.Errorf("Expected the retry marked as practice, got %+v", embed.Footer)
+	}
+	stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+	if stats.TotalAnswers != 1 || stats.HighestScore == 100 {
+		t.Errorf("Expected only the first attempt counted, got %+v", stats)
+	}
+}
+
+func TestStreakMilestone(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	quiz := session.lastMessage()
+
+	// Answers on each of the last six days
+	conn, err := sql.Open("sqlite", b.config.Database.Path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer conn.Close()
+	for day := 1; day < 7; day++ {
+		answeredAt := time.Now().AddDate(0, 0, -day).UTC().Format("2006-01-02 15:04:05")
+		conn.Exec("INSERT INTO answers (discord_id, guild_id, question_id, user_answer, score, answered_at) VALUES (?, ?, 1, 'a', 80, ?)", testUserID, testGuildID, answeredAt)
+	}
+
+	// Only the first counted answer of the day is congratulated, not the practice after it
+	b.onMessageCreate(nil, replyTo(quiz, "I drink coffee."))
+	b.onMessageCreate(nil, replyTo(quiz, "I drink coffee every morning."))
+
+	var milestones int
+	for _, m := range session.sent {
+		if strings.Contains(m.Content, "7日連続") {
+			milestones++
+		}
+	}
+	if milestones != 1 {
+		t.Errorf("Expected one streak milestone, got %d in %+v", milestones, session.sent)
+	}
+}
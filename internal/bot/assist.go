@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+)
+
+// hintPenalty is subtracted from the score of answers given after taking a hint
+const hintPenalty = 10
+
+// createHintButton creates the button that shows a hint for the quiz
+func createHintButton() discordgo.Button {
+	return discordgo.Button{
+		Label:    "💡 ヒント",
+		Style:    discordgo.SecondaryButton,
+		CustomID: "quiz_hint",
+	}
+}
+
+// createRevealButton creates the button that reveals the model answer
+func createRevealButton() discordgo.Button {
+	return discordgo.Button{
+		Label:    "👀 答えを見る",
+		Style:    discordgo.DangerButton,
+		CustomID: "quiz_reveal",
+	}
+}
+
+// handleHintButton shows key vocabulary and a grammar pattern for the quiz
+// only to the user who asked, and records that they took a hint
+func (b *Bot) handleHintButton(i *discordgo.InteractionCreate) {
+	quiz := b.quizForClick(i)
+	if quiz == nil {
+		return
+	}
+
+	b.deferResponse(i, true)
+
+	question, err := b.db.GetQuestion(quiz.QuestionID)
+	if err != nil {
+		log.Printf("Error getting question: %v", err)
+		b.respondError(i, "❌ この問題は見つかりませんでした")
+		return
+	}
+
+	hint := question.Hint
+	if hint == "" {
+		hint, err = b.model.GenerateHint(b.modelContext(question.GuildID), question.Japanese, question.Difficulty, claude.Direction(question.Direction))
+		if err != nil {
+			log.Printf("Error generating hint: %v", err)
+			b.respondError(i, "❌ ヒントの生成に失敗しました")
+			return
+		}
+		if err := b.db.SaveQuestionHint(question.ID, hint); err != nil {
+			log.Printf("Error saving hint: %v", err)
+		}
+	}
+
+	if err := b.db.RecordHint(interactionUserID(i), question.GuildID, question.ID); err != nil {
+		log.Printf("Error recording hint: %v", err)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("💡 ヒント（問題 #%d）", question.ID),
+		Description: truncate(hint, 4096),
+		Color:       0xFFD166,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("ヒントを使った回答は %d 点減点されます", hintPenalty),
+		},
+	}
+	if _, err := b.session.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	}); err != nil {
+		log.Printf("Error sending hint: %v", err)
+	}
+}
+
+// handleRevealButton shows the model answer only to the user who asked. The
+// question is added to their review queue and later answers to it are not
+// counted as attempts until /review serves it again.
+func (b *Bot) handleRevealButton(i *discordgo.InteractionCreate) {
+	quiz := b.quizForClick(i)
+	if quiz == nil {
+		return
+	}
+
+	b.deferResponse(i, true)
+
+	question, err := b.db.GetQuestion(quiz.QuestionID)
+	if err != nil {
+		log.Printf("Error getting question: %v", err)
+		b.respondError(i, "❌ この問題は見つかりませんでした")
+		return
+	}
+
+	modelAnswer := question.ModelAnswer
+	if modelAnswer == "" {
+		modelAnswer, err = b.model.GenerateModelAnswer(context.Background(), question.Japanese, claude.Direction(question.Direction))
+		if err != nil {
+			log.Printf("Error generating model answer: %v", err)
+			b.respondError(i, "❌ 模範解答の生成に失敗しました")
+			return
+		}
+		if err := b.db.SaveQuestionModelAnswer(question.ID, modelAnswer); err != nil {
+			log.Printf("Error saving model answer: %v", err)
+		}
+	}
+
+	userID := interactionUserID(i)
+	if err := b.db.RecordReveal(userID, question.GuildID, question.ID); err != nil {
+		log.Printf("Error recording reveal: %v", err)
+	}
+
+	// A revealed question counts as one the user struggled with
+	if err := b.db.RecordReview(userID, question.ID, 0); err != nil {
+		log.Printf("Error recording review: %v", err)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("👀 模範解答（問題 #%d）", question.ID),
+		Color: 0xFF6B6B,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "問題", Value: fmt.Sprintf("「%s」", question.Japanese)},
+			{Name: "📖 模範解答", Value: truncate(modelAnswer, 1024)},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "この問題は復習リストに追加されました。以降の回答は練習として扱われ、統計には記録されません",
+		},
+	}
+	if _, err := b.session.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	}); err != nil {
+		log.Printf("Error sending model answer: %v", err)
+	}
+}
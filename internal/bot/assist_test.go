@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+func TestHintButton(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	quiz := session.lastMessage()
+
+	b.onInteractionCreate(nil, clickOn(quiz, "quiz_hint"))
+
+	resp := session.lastResponse()
+	if resp.Data == nil || resp.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Errorf("Expected an ephemeral response, got %+v", resp)
+	}
+	edit := session.lastEdit()
+	if edit == nil || edit.Embeds == nil || !strings.Contains((*edit.Embeds)[0].Description, "「I」") {
+		t.Fatalf("Expected the hint, got %+v", edit)
+	}
+
+	// A perfect answer after a hint loses the penalty
+	b.onInteractionCreate(nil, modalSubmit(answerModalPrefix+quiz.ID, map[string]string{
+		"answer_input": "I drink coffee every morning.",
+	}))
+
+	stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+	if stats.HintsUsed != 1 || stats.HighestScore != 100-hintPenalty {
+		t.Errorf("Expected a penalised answer after a hint, got %+v", stats)
+	}
+}
+
+func TestAssistButtons_InDM(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	quiz := session.lastMessage()
+
+	// Help taken by DM on a guild quiz counts towards that guild
+	b.onInteractionCreate(nil, inDM(clickOn(quiz, "quiz_hint")))
+	b.onInteractionCreate(nil, inDM(clickOn(quiz, "quiz_reveal")))
+
+	stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+	if stats.HintsUsed != 1 || stats.Revealed != 1 {
+		t.Errorf("Expected the help recorded in the quiz's guild, got %+v", stats)
+	}
+}
+
+func TestRevealButton(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	quiz := session.lastMessage()
+
+	b.onInteractionCreate(nil, clickOn(quiz, "quiz_reveal"))
+
+	edit := session.lastEdit()
+	if edit == nil || edit.Embeds == nil || (*edit.Embeds)[0].Fields[1].Value != "I drink coffee every morning." {
+		t.Fatalf("Expected the model answer, got %+v", edit)
+	}
+	if count, _ := b.db.CountDueReviews(testUserID, testGuildID); count != 0 {
+		t.Errorf("Expected the review to be due tomorrow, got %d due now", count)
+	}
+
+	b.onInteractionCreate(nil, modalSubmit(answerModalPrefix+quiz.ID, map[string]string{
+		"answer_input": "I drink coffee every morning.",
+	}))
+
+	stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+	if stats.TotalAnswers != 0 || stats.Revealed != 1 {
+		t.Errorf("Expected the revealed question not to count as an attempt, got %+v", stats)
+	}
+}
+
+func TestRevealButton_ReviewedLater(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	b.onInteractionCreate(nil, clickOn(session.lastMessage(), "quiz_reveal"))
+	makeReviewsDue(t, b)
+
+	b.onInteractionCreate(nil, slashCommand("review"))
+	review := session.lastMessage()
+	if review == nil || !strings.Contains(review.Content, "復習問題") {
+		t.Fatalf("Expected the revealed question served for review, got %+v", review)
+	}
+	b.onMessageCreate(nil, replyTo(review, "I drink coffee every morning."))
+
+	if count, _ := b.db.CountDueReviews(testUserID, testGuildID); count != 0 {
+		t.Errorf("Expected the review rescheduled after answering it, got %d due", count)
+	}
+	stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+	if stats.TotalAnswers != 1 {
+		t.Errorf("Expected the review answer counted as an attempt, got %+v", stats)
+	}
+}
+
+// makeReviewsDue moves every scheduled review of the test bot into the past
+func makeReviewsDue(t *testing.T, b *Bot) {
+	t.Helper()
+
+	conn, err := sql.Open("sqlite", b.config.Database.Path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Exec("UPDATE reviews SET due_at = ?", time.Now().Add(-time.Hour).UTC().Format("2006-01-02 15:04:05")); err != nil {
+		t.Fatalf("Failed to update due dates: %v", err)
+	}
+}
+
+func TestHintButton_OtherUser(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	quiz := session.lastMessage()
+
+	click := clickOn(quiz, "quiz_hint")
+	click.Member = testMember("someone-else")
+	b.onInteractionCreate(nil, click)
+
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "他のユーザー宛て") {
+		t.Errorf("Expected a warning, got %+v", resp.Data)
+	}
+}
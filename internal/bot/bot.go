@@ -2,6 +2,7 @@ package bot
 
 import (
 	"log"
+	"sync"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/melophe/Discord-ENG/internal/claude"
@@ -11,44 +12,56 @@ import (
 
 // Bot represents the Discord bot
 type Bot struct {
-	session   *discordgo.Session
+	conn      *discordgo.Session
+	session   Session
 	config    *config.Config
 	db        *db.DB
-	claude    *claude.Client
+	model     claude.QuizModel
 	channelID string
+	userID    string
+
+	// Guild of the fallback channel, looked up once
+	fallbackMu      sync.Mutex
+	fallbackGuildID string
 }
 
 // New creates a new Bot instance
-func New(cfg *config.Config, database *db.DB, claudeClient *claude.Client) (*Bot, error) {
-	session, err := discordgo.New("Bot " + cfg.Discord.Token)
+func New(cfg *config.Config, database *db.DB, model claude.QuizModel) (*Bot, error) {
+	conn, err := discordgo.New("Bot " + cfg.Discord.Token)
 	if err != nil {
 		return nil, err
 	}
 
-	bot := &Bot{
-		session:   session,
-		config:    cfg,
-		db:        database,
-		claude:    claudeClient,
-		channelID: cfg.Discord.ChannelID,
-	}
+	bot := newBot(conn, cfg, database, model)
+	bot.conn = conn
 
 	// Register handlers
-	session.AddHandler(bot.onReady)
-	session.AddHandler(bot.onInteractionCreate)
-	session.AddHandler(bot.onMessageCreate)
+	conn.AddHandler(bot.onReady)
+	conn.AddHandler(bot.onInteractionCreate)
+	conn.AddHandler(bot.onMessageCreate)
 
 	// Set intents
-	session.Identify.Intents = discordgo.IntentsGuildMessages |
+	conn.Identify.Intents = discordgo.IntentsGuildMessages |
 		discordgo.IntentsMessageContent |
 		discordgo.IntentsDirectMessages
 
 	return bot, nil
 }
 
+// newBot creates a Bot that talks to Discord through the given session
+func newBot(session Session, cfg *config.Config, database *db.DB, model claude.QuizModel) *Bot {
+	return &Bot{
+		session:   session,
+		config:    cfg,
+		db:        database,
+		model:     model,
+		channelID: cfg.Discord.ChannelID,
+	}
+}
+
 // Start opens the Discord connection and registers commands
 func (b *Bot) Start() error {
-	if err := b.session.Open(); err != nil {
+	if err := b.conn.Open(); err != nil {
 		return err
 	}
 
@@ -57,28 +70,63 @@ func (b *Bot) Start() error {
 		return err
 	}
 
+	// Post results for challenges that were open when the bot last stopped
+	b.resumeChallenges()
+
 	log.Println("Bot is running!")
 	return nil
 }
 
 // Stop closes the Discord connection
 func (b *Bot) Stop() error {
-	return b.session.Close()
+	return b.conn.Close()
 }
 
 // Session returns the Discord session
-func (b *Bot) Session() *discordgo.Session {
+func (b *Bot) Session() Session {
 	return b.session
 }
 
-// ChannelID returns the configured channel ID
+// ChannelID returns the fallback channel ID from the environment, used for
+// scheduled quizzes of users who chose channel delivery. It may be empty.
 func (b *Bot) ChannelID() string {
 	return b.channelID
 }
 
+// fallbackChannel returns the fallback channel if it belongs to the guild, or
+// an empty string otherwise
+func (b *Bot) fallbackChannel(guildID string) string {
+	if b.channelID == "" || guildID == "" || b.fallbackGuild() != guildID {
+		return ""
+	}
+	return b.channelID
+}
+
+// fallbackGuild returns the guild of the fallback channel. It is looked up
+// once and cached; a failed lookup is retried on the next call.
+func (b *Bot) fallbackGuild() string {
+	b.fallbackMu.Lock()
+	defer b.fallbackMu.Unlock()
+
+	if b.fallbackGuildID == "" {
+		channel, err := b.session.Channel(b.channelID)
+		if err != nil {
+			log.Printf("Error getting fallback channel: %v", err)
+			return ""
+		}
+		b.fallbackGuildID = channel.GuildID
+	}
+	return b.fallbackGuildID
+}
+
 // onReady is called when the bot is ready
 func (b *Bot) onReady(s *discordgo.Session, r *discordgo.Ready) {
+	b.userID = r.User.ID
 	log.Printf("Logged in as: %v#%v", s.State.User.Username, s.State.User.Discriminator)
+
+	if b.channelID != "" {
+		b.fallbackGuild()
+	}
 }
 
 // registerCommands registers slash commands
@@ -86,7 +134,36 @@ func (b *Bot) registerCommands() error {
 	commands := []*discordgo.ApplicationCommand{
 		{
 			Name:        "quiz",
-			Description: "Get a new English translation quiz",
+			Description: "Get a new English translation quiz or another exercise",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "type",
+					Description: "The kind of exercise (default: translation)",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "翻訳 (translation)", Value: string(claude.ExerciseTranslation)},
+						{Name: "穴埋め (fill in the blank)", Value: string(claude.ExerciseCloze)},
+						{Name: "4択 (multiple choice)", Value: string(claude.ExerciseChoice)},
+						{Name: "並べ替え (word order)", Value: string(claude.ExerciseReorder)},
+						{Name: "誤り訂正 (error correction)", Value: string(claude.ExerciseCorrection)},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "focus",
+					Description: "Target your weak spots or phrases from your vocabulary notebook (translation only)",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "🎯 苦手克服 (weak spots)", Value: focusWeaknesses},
+						{Name: "📌 単語帳 (vocabulary notebook)", Value: focusVocabulary},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "grammar",
+					Description: "A grammar point to practise, e.g. present perfect (translation only)",
+					MaxLength:   100,
+				},
+			},
 		},
 		{
 			Name:        "theme",
@@ -103,15 +180,40 @@ func (b *Bot) registerCommands() error {
 		{
 			Name:        "stats",
 			Description: "View your learning statistics",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "scope",
+					Description: "Count answers from this server only or from all servers",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "このサーバー (server)", Value: string(db.ScopeServer)},
+						{Name: "全サーバー (global)", Value: string(db.ScopeGlobal)},
+					},
+				},
+			},
 		},
 		{
 			Name:        "settings",
 			Description: "View or change your settings",
 		},
+		{
+			Name:        "schedule",
+			Description: "Set when scheduled quizzes are delivered and your time zone",
+		},
+		{
+			Name:        "review",
+			Description: "Review a question you struggled with before",
+		},
+		setupCommand,
+		leaderboardCommand,
+		challengeCommand,
+		vocabCommand,
+		flashcardsCommand,
+		weaknessesCommand,
 	}
 
 	for _, cmd := range commands {
-		_, err := b.session.ApplicationCommandCreate(b.session.State.User.ID, "", cmd)
+		_, err := b.conn.ApplicationCommandCreate(b.conn.State.User.ID, "", cmd)
 		if err != nil {
 			log.Printf("Cannot create command %v: %v", cmd.Name, err)
 			return err
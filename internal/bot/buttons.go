@@ -1,95 +1,81 @@
 package bot
 
 import (
-	"context"
 	"fmt"
-	"log"
+	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+	"github.com/melophe/Discord-ENG/internal/schedule"
 )
 
 // handleComponentInteraction handles button and select menu interactions
-func (b *Bot) handleComponentInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *Bot) handleComponentInteraction(i *discordgo.InteractionCreate) {
 	customID := i.MessageComponentData().CustomID
 
 	switch customID {
 	case "quiz_next":
-		b.handleNextQuizButton(s, i)
+		b.handleNextQuizButton(i)
 	case "settings_open":
-		b.handleSettingsButton(s, i)
+		b.handleSettingsButton(i)
 	case "stats_show":
-		b.handleStatsButton(s, i)
+		b.handleStatsButton(i)
 	case "difficulty_select":
-		b.handleDifficultySelect(s, i)
+		b.handleDifficultySelect(i)
+	case "direction_select":
+		b.handleDirectionSelect(i)
+	case "choice_select":
+		b.handleChoiceSelect(i)
 	case "theme_modal":
-		b.handleThemeModalButton(s, i)
+		b.handleThemeModalButton(i)
 	case "schedule_toggle":
-		b.handleScheduleToggle(s, i)
+		b.handleScheduleToggle(i)
+	case "schedule_modal":
+		b.handleScheduleModalButton(i)
+	case "delivery_toggle":
+		b.handleDeliveryToggle(i)
+	case "answer_open":
+		b.handleAnswerButton(i)
+	case "quiz_hint":
+		b.handleHintButton(i)
+	case "quiz_reveal":
+		b.handleRevealButton(i)
+	default:
+		if strings.HasPrefix(customID, leaderboardPrefix) {
+			b.handleLeaderboardButton(i, customID)
+		} else if strings.HasPrefix(customID, flashcardPrefix) {
+			b.handleFlashcardButton(i, customID)
+		} else if messageID, ok := strings.CutPrefix(customID, retryPrefix); ok {
+			b.handleRetryButton(i, messageID)
+		} else if messageID, ok := strings.CutPrefix(customID, vocabSavePrefix); ok {
+			b.handleVocabSaveButton(i, messageID)
+		} else if scope, ok := strings.CutPrefix(customID, weakQuizPrefix); ok {
+			b.handleWeakQuizButton(i, db.StatsScope(scope))
+		}
 	}
 }
 
 // handleNextQuizButton generates a new quiz
-func (b *Bot) handleNextQuizButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
-	})
-
-	userID := i.Member.User.ID
-	user, err := b.db.GetOrCreateUser(userID)
-	if err != nil {
-		log.Printf("Error getting user: %v", err)
-		return
-	}
-
-	ctx := context.Background()
-	japanese, err := b.claude.GenerateQuestion(ctx, user.Theme, user.Difficulty)
-	if err != nil {
-		log.Printf("Error generating question: %v", err)
-		msg := "問題の生成に失敗しました"
-		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
-		return
-	}
-
-	questionID, _ := b.db.SaveQuestion(japanese, user.Difficulty, user.Theme)
-
-	embed := b.createQuizEmbed(questionID, japanese, user.Theme, user.Difficulty)
-	components := b.createQuizButtons()
-
-	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Embeds:     &[]*discordgo.MessageEmbed{embed},
-		Components: &components,
-	})
+func (b *Bot) handleNextQuizButton(i *discordgo.InteractionCreate) {
+	b.sendNewQuiz(i, claude.Focus{})
 }
 
 // handleSettingsButton shows settings
-func (b *Bot) handleSettingsButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	userID := i.Member.User.ID
+func (b *Bot) handleSettingsButton(i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
 
-	user, err := b.db.GetOrCreateUser(userID)
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
 	if err != nil {
-		b.respondComponentMessage(s, i, "設定の取得に失敗しました")
+		b.respondComponentMessage(i, "設定の取得に失敗しました")
 		return
 	}
 
-	difficultyLabel := map[string]string{
-		"beginner":     "初級",
-		"intermediate": "中級",
-		"advanced":     "上級",
-	}[user.Difficulty]
-
-	embed := &discordgo.MessageEmbed{
-		Title: "⚙️ 現在の設定",
-		Color: 0x5865F2,
-		Fields: []*discordgo.MessageEmbedField{
-			{Name: "難易度", Value: difficultyLabel, Inline: true},
-			{Name: "テーマ", Value: user.Theme, Inline: true},
-			{Name: "定期出題", Value: map[bool]string{true: "ON", false: "OFF"}[user.ScheduleEnabled], Inline: true},
-		},
-	}
-
+	embed := b.createSettingsEmbed(user)
 	components := b.createSettingsButtons()
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
 			Embeds:     []*discordgo.MessageEmbed{embed},
@@ -100,27 +86,18 @@ func (b *Bot) handleSettingsButton(s *discordgo.Session, i *discordgo.Interactio
 }
 
 // handleStatsButton shows statistics
-func (b *Bot) handleStatsButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	userID := i.Member.User.ID
+func (b *Bot) handleStatsButton(i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
 
-	stats, err := b.db.GetUserStats(userID)
+	stats, err := b.db.GetUserStats(userID, i.GuildID, db.ScopeServer)
 	if err != nil {
-		b.respondComponentMessage(s, i, "統計の取得に失敗しました")
+		b.respondComponentMessage(i, "統計の取得に失敗しました")
 		return
 	}
 
-	embed := &discordgo.MessageEmbed{
-		Title: "📊 あなたの学習統計",
-		Color: 0x00D4AA,
-		Fields: []*discordgo.MessageEmbedField{
-			{Name: "総回答数", Value: fmt.Sprintf("%d 問", stats.TotalAnswers), Inline: true},
-			{Name: "平均スコア", Value: fmt.Sprintf("%.1f 点", stats.AverageScore), Inline: true},
-			{Name: "最高スコア", Value: fmt.Sprintf("%d 点", stats.HighestScore), Inline: true},
-			{Name: "今日の回答", Value: fmt.Sprintf("%d 問", stats.AnswersToday), Inline: true},
-		},
-	}
+	embed := b.createStatsEmbed(stats, db.ScopeServer)
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
 			Embeds: []*discordgo.MessageEmbed{embed},
@@ -130,39 +107,77 @@ func (b *Bot) handleStatsButton(s *discordgo.Session, i *discordgo.InteractionCr
 }
 
 // handleDifficultySelect handles difficulty selection
-func (b *Bot) handleDifficultySelect(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *Bot) handleDifficultySelect(i *discordgo.InteractionCreate) {
 	values := i.MessageComponentData().Values
 	if len(values) == 0 {
 		return
 	}
 
 	difficulty := values[0]
-	userID := i.Member.User.ID
+	userID := interactionUserID(i)
 
-	user, err := b.db.GetOrCreateUser(userID)
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
 	if err != nil {
-		b.respondComponentMessage(s, i, "エラーが発生しました")
+		b.respondComponentMessage(i, "エラーが発生しました")
 		return
 	}
 
-	err = b.db.UpdateUserSettings(userID, difficulty, user.Theme)
+	err = b.db.UpdateUserSettings(userID, i.GuildID, difficulty, user.Theme)
 	if err != nil {
-		b.respondComponentMessage(s, i, "設定の更新に失敗しました")
+		b.respondComponentMessage(i, "設定の更新に失敗しました")
 		return
 	}
 
-	difficultyLabel := map[string]string{
-		"beginner":     "初級",
-		"intermediate": "中級",
-		"advanced":     "上級",
-	}[difficulty]
+	difficultyLabel := difficultyName(difficulty)
+
+	if difficulty == db.DifficultyAuto {
+		// Start from the level matching the previous fixed difficulty
+		level := user.Level
+		if level == "" {
+			level = db.InitialLevel(user.Difficulty)
+			if err := b.db.SetUserLevel(userID, i.GuildID, level, 0); err != nil {
+				b.respondComponentMessage(i, "設定の更新に失敗しました")
+				return
+			}
+		}
+		b.respondComponentMessage(i, fmt.Sprintf("✅ 難易度を「%s」に設定しました！現在のレベルは **%s** です。直近 %d 問の成績に合わせて自動で上下します。", difficultyLabel, difficultyName(level), db.LevelWindow))
+		return
+	}
 
-	b.respondComponentMessage(s, i, fmt.Sprintf("✅ 難易度を「%s」に設定しました！", difficultyLabel))
+	b.respondComponentMessage(i, fmt.Sprintf("✅ 難易度を「%s」に設定しました！", difficultyLabel))
+}
+
+// handleDirectionSelect handles translation direction selection
+func (b *Bot) handleDirectionSelect(i *discordgo.InteractionCreate) {
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return
+	}
+
+	direction := values[0]
+	label, ok := directionLabels[direction]
+	if !ok {
+		b.respondComponentMessage(i, "エラーが発生しました")
+		return
+	}
+
+	userID := interactionUserID(i)
+	if _, err := b.db.GetOrCreateUser(userID, i.GuildID); err != nil {
+		b.respondComponentMessage(i, "エラーが発生しました")
+		return
+	}
+
+	if err := b.db.UpdateUserDirection(userID, i.GuildID, direction); err != nil {
+		b.respondComponentMessage(i, "設定の更新に失敗しました")
+		return
+	}
+
+	b.respondComponentMessage(i, fmt.Sprintf("✅ 出題方向を「%s」に設定しました！", label))
 }
 
 // handleThemeModalButton opens the theme input modal
-func (b *Bot) handleThemeModalButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+func (b *Bot) handleThemeModalButton(i *discordgo.InteractionCreate) {
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseModal,
 		Data: &discordgo.InteractionResponseData{
 			CustomID: "theme_modal_submit",
@@ -187,19 +202,19 @@ func (b *Bot) handleThemeModalButton(s *discordgo.Session, i *discordgo.Interact
 }
 
 // handleScheduleToggle toggles the schedule setting
-func (b *Bot) handleScheduleToggle(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	userID := i.Member.User.ID
+func (b *Bot) handleScheduleToggle(i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
 
-	user, err := b.db.GetOrCreateUser(userID)
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
 	if err != nil {
-		b.respondComponentMessage(s, i, "エラーが発生しました")
+		b.respondComponentMessage(i, "エラーが発生しました")
 		return
 	}
 
 	newEnabled := !user.ScheduleEnabled
-	err = b.db.UpdateUserSchedule(userID, newEnabled)
+	err = b.db.UpdateUserSchedule(userID, i.GuildID, newEnabled)
 	if err != nil {
-		b.respondComponentMessage(s, i, "設定の更新に失敗しました")
+		b.respondComponentMessage(i, "設定の更新に失敗しました")
 		return
 	}
 
@@ -207,47 +222,167 @@ func (b *Bot) handleScheduleToggle(s *discordgo.Session, i *discordgo.Interactio
 	if newEnabled {
 		status = "ON"
 	}
-	b.respondComponentMessage(s, i, fmt.Sprintf("✅ 定期出題を %s にしました！", status))
+	b.respondComponentMessage(i, fmt.Sprintf("✅ 定期出題を %s にしました！", status))
 }
 
-// handleModalSubmit handles modal form submissions
-func (b *Bot) handleModalSubmit(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if i.ModalSubmitData().CustomID != "theme_modal_submit" {
+// handleDeliveryToggle switches quiz delivery between the channel and DMs
+func (b *Bot) handleDeliveryToggle(i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
+
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
+	if err != nil {
+		b.respondComponentMessage(i, "エラーが発生しました")
 		return
 	}
 
-	var theme string
-	for _, row := range i.ModalSubmitData().Components {
-		for _, comp := range row.(*discordgo.ActionsRow).Components {
-			if input, ok := comp.(*discordgo.TextInput); ok && input.CustomID == "theme_input" {
-				theme = input.Value
-			}
+	delivery, label := db.DeliveryDM, "DM"
+	if user.Delivery == db.DeliveryDM {
+		delivery, label = db.DeliveryChannel, "チャンネル"
+	}
+
+	if err := b.db.UpdateUserDelivery(userID, i.GuildID, delivery); err != nil {
+		b.respondComponentMessage(i, "設定の更新に失敗しました")
+		return
+	}
+
+	b.respondComponentMessage(i, fmt.Sprintf("✅ 問題の配信先を%sにしました！", label))
+}
+
+// handleScheduleModalButton opens the delivery schedule modal
+func (b *Bot) handleScheduleModalButton(i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
+
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
+	if err != nil {
+		b.respondComponentMessage(i, "エラーが発生しました")
+		return
+	}
+
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "schedule_modal_submit",
+			Title:    "配信時刻を設定",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "schedule_input",
+							Label:       "配信時刻（空欄で一定間隔）",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "例: weekdays 07:30,21:00 / mon,wed,fri 12:00",
+							Value:       user.Schedule,
+							Required:    false,
+							MaxLength:   100,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "timezone_input",
+							Label:       "タイムゾーン",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "例: Asia/Tokyo, America/New_York",
+							Value:       user.Timezone,
+							Required:    true,
+							MinLength:   1,
+							MaxLength:   50,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handleModalSubmit handles modal form submissions
+func (b *Bot) handleModalSubmit(i *discordgo.InteractionCreate) {
+	customID := i.ModalSubmitData().CustomID
+
+	switch customID {
+	case "theme_modal_submit":
+		b.handleThemeModalSubmit(i)
+	case "schedule_modal_submit":
+		b.handleScheduleModalSubmit(i)
+	default:
+		if messageID, ok := strings.CutPrefix(customID, answerModalPrefix); ok {
+			b.handleAnswerModalSubmit(i, messageID)
 		}
 	}
+}
 
+// handleThemeModalSubmit saves the theme entered in the theme modal
+func (b *Bot) handleThemeModalSubmit(i *discordgo.InteractionCreate) {
+	theme := modalValue(i, "theme_input")
 	if theme == "" {
-		b.respondComponentMessage(s, i, "テーマを入力してください")
+		b.respondComponentMessage(i, "テーマを入力してください")
 		return
 	}
 
-	userID := i.Member.User.ID
-	user, err := b.db.GetOrCreateUser(userID)
+	userID := interactionUserID(i)
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
 	if err != nil {
-		b.respondComponentMessage(s, i, "エラーが発生しました")
+		b.respondComponentMessage(i, "エラーが発生しました")
 		return
 	}
 
-	err = b.db.UpdateUserSettings(userID, user.Difficulty, theme)
+	err = b.db.UpdateUserSettings(userID, i.GuildID, user.Difficulty, theme)
 	if err != nil {
-		b.respondComponentMessage(s, i, "設定の更新に失敗しました")
+		b.respondComponentMessage(i, "設定の更新に失敗しました")
+		return
+	}
+
+	b.respondComponentMessage(i, fmt.Sprintf("✅ テーマを「%s」に設定しました！", theme))
+}
+
+// handleScheduleModalSubmit validates and saves the delivery schedule and time zone
+func (b *Bot) handleScheduleModalSubmit(i *discordgo.InteractionCreate) {
+	spec := strings.TrimSpace(modalValue(i, "schedule_input"))
+	timezone := strings.TrimSpace(modalValue(i, "timezone_input"))
+
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "" {
+		b.respondComponentMessage(i, fmt.Sprintf("❌ タイムゾーン「%s」が見つかりません（例: Asia/Tokyo）", timezone))
 		return
 	}
+	if spec != "" {
+		if _, err := schedule.Parse(spec); err != nil {
+			b.respondComponentMessage(i, fmt.Sprintf("❌ 配信時刻の形式が正しくありません: %v", err))
+			return
+		}
+	}
 
-	b.respondComponentMessage(s, i, fmt.Sprintf("✅ テーマを「%s」に設定しました！", theme))
+	userID := interactionUserID(i)
+	if _, err := b.db.GetOrCreateUser(userID, i.GuildID); err != nil {
+		b.respondComponentMessage(i, "エラーが発生しました")
+		return
+	}
+
+	if err := b.db.UpdateUserDeliveryTimes(userID, i.GuildID, timezone, spec); err != nil {
+		b.respondComponentMessage(i, "設定の更新に失敗しました")
+		return
+	}
+
+	if spec == "" {
+		spec = fmt.Sprintf("%d分ごと", b.config.Schedule.IntervalMinutes)
+	}
+	b.respondComponentMessage(i, fmt.Sprintf("✅ 配信時刻を「%s」（%s）に設定しました！", spec, timezone))
+}
+
+// modalValue returns the value of a text input in a submitted modal
+func modalValue(i *discordgo.InteractionCreate, customID string) string {
+	for _, row := range i.ModalSubmitData().Components {
+		for _, comp := range row.(*discordgo.ActionsRow).Components {
+			if input, ok := comp.(*discordgo.TextInput); ok && input.CustomID == customID {
+				return input.Value
+			}
+		}
+	}
+	return ""
 }
 
-func (b *Bot) respondComponentMessage(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+func (b *Bot) respondComponentMessage(i *discordgo.InteractionCreate, message string) {
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
 			Content: message,
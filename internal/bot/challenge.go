@@ -0,0 +1,295 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// defaultChallengeMinutes is how long a /challenge stays open when neither the
+// command nor the guild sets a duration
+const defaultChallengeMinutes = 10
+
+// challengeCommand is the /challenge command that starts a group challenge
+var challengeCommand = &discordgo.ApplicationCommand{
+	Name:        "challenge",
+	Description: "Start a timed challenge: everyone answers, results are ranked at the end",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "minutes",
+			Description: "How long to accept answers",
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+			MaxValue:    60,
+		},
+	},
+}
+
+// challengeResult is an evaluated challenge entry. result is nil if the entry
+// could not be graded.
+type challengeResult struct {
+	entry  *db.ChallengeEntry
+	result *claude.EvaluationResult
+}
+
+// handleChallengeCommand posts a challenge question in the current channel
+func (b *Bot) handleChallengeCommand(i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		b.respondComponentMessage(i, "❌ /challenge はサーバー内でのみ使用できます")
+		return
+	}
+
+	guild, err := b.db.GetGuild(i.GuildID)
+	if err != nil {
+		log.Printf("Error getting guild: %v", err)
+		b.respondComponentMessage(i, "エラーが発生しました")
+		return
+	}
+
+	minutes := guild.ChallengeMinutes
+	if minutes <= 0 {
+		minutes = defaultChallengeMinutes
+	}
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "minutes" {
+			minutes = int(opt.IntValue())
+		}
+	}
+
+	b.deferResponse(i, false)
+
+	ctx := context.Background()
+	japanese, err := b.model.GenerateQuestion(ctx, guild.Theme, guild.Difficulty, claude.JapaneseToEnglish, claude.Focus{})
+	if err != nil {
+		log.Printf("Error generating question: %v", err)
+		b.respondError(i, "問題の生成に失敗しました")
+		return
+	}
+
+	questionID, err := b.db.SaveQuestion(i.GuildID, japanese, guild.Difficulty, guild.Theme, db.DirectionJaEn)
+	if err != nil {
+		log.Printf("Error saving question: %v", err)
+		b.respondError(i, "問題の保存に失敗しました")
+		return
+	}
+
+	endsAt := time.Now().Add(time.Duration(minutes) * time.Minute)
+	embed := b.createChallengeEmbed(questionID, japanese, guild.Theme, guild.Difficulty, endsAt)
+	components := createChallengeButtons()
+	msg, err := b.session.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	})
+	if err != nil {
+		log.Printf("Error sending challenge: %v", err)
+		return
+	}
+
+	b.startChallenge(msg, i.GuildID, questionID, endsAt)
+}
+
+// startChallenge records a posted challenge message and schedules its results
+func (b *Bot) startChallenge(msg *discordgo.Message, guildID string, questionID int64, endsAt time.Time) {
+	b.recordQuizMessage(msg, questionID, "")
+
+	challenge := &db.Challenge{
+		MessageID:  msg.ID,
+		GuildID:    guildID,
+		ChannelID:  msg.ChannelID,
+		QuestionID: questionID,
+		EndsAt:     endsAt,
+	}
+	if err := b.db.SaveChallenge(challenge); err != nil {
+		log.Printf("Error saving challenge: %v", err)
+		return
+	}
+
+	b.scheduleChallengeClose(challenge)
+}
+
+// scheduleChallengeClose closes the challenge when it ends
+func (b *Bot) scheduleChallengeClose(c *db.Challenge) {
+	time.AfterFunc(time.Until(c.EndsAt), func() { b.closeChallenge(c) })
+}
+
+// resumeChallenges schedules results for challenges left open by a restart.
+// Challenges that ended while the bot was down are closed right away.
+func (b *Bot) resumeChallenges() {
+	challenges, err := b.db.GetOpenChallenges()
+	if err != nil {
+		log.Printf("Error getting open challenges: %v", err)
+		return
+	}
+	for _, c := range challenges {
+		b.scheduleChallengeClose(c)
+	}
+}
+
+// handleChallengeReply collects an answer to an open challenge. Answers are
+// acknowledged with a reaction and evaluated when the challenge closes.
+func (b *Bot) handleChallengeReply(m *discordgo.MessageCreate, c *db.Challenge) {
+	accepted, err := b.acceptChallengeEntry(c, m.Author.ID, m.Content)
+	if err != nil {
+		log.Printf("Error saving challenge entry: %v", err)
+		b.session.ChannelMessageSend(m.ChannelID, "❌ 回答の受付に失敗しました")
+		return
+	}
+	if !accepted {
+		b.session.ChannelMessageSend(m.ChannelID, fmt.Sprintf("⏱️ <@%s> このチャレンジは締め切られました", m.Author.ID))
+		return
+	}
+
+	if err := b.session.MessageReactionAdd(m.ChannelID, m.ID, "✅"); err != nil {
+		log.Printf("Error acknowledging challenge entry: %v", err)
+	}
+}
+
+// acceptChallengeEntry records a user's answer to a challenge, reporting
+// false if the challenge has already closed
+func (b *Bot) acceptChallengeEntry(c *db.Challenge, userID, answer string) (bool, error) {
+	if c.Closed || time.Now().After(c.EndsAt) {
+		return false, nil
+	}
+	return true, b.db.SaveChallengeEntry(c.MessageID, userID, answer)
+}
+
+// closeChallenge evaluates every entry of a challenge like any other answer
+// and posts the ranked results, listing entries that failed to grade last
+func (b *Bot) closeChallenge(c *db.Challenge) {
+	closed, err := b.db.CloseChallenge(c.MessageID)
+	if err != nil {
+		log.Printf("Error closing challenge: %v", err)
+		return
+	}
+	if !closed {
+		return
+	}
+
+	question, err := b.db.GetQuestion(c.QuestionID)
+	if err != nil {
+		log.Printf("Error getting question: %v", err)
+		return
+	}
+
+	entries, err := b.db.GetChallengeEntries(c.MessageID)
+	if err != nil {
+		log.Printf("Error getting challenge entries: %v", err)
+		return
+	}
+
+	quiz := &db.QuizMessage{MessageID: c.MessageID, QuestionID: c.QuestionID}
+	results := make([]challengeResult, len(entries))
+	for n, entry := range entries {
+		results[n].entry = entry
+		eval, err := b.evaluateAnswer(quiz, entry.DiscordID, entry.Answer)
+		if err != nil {
+			log.Printf("Error evaluating challenge entry from %s: %v", entry.DiscordID, err)
+			continue
+		}
+		results[n].result = eval.result
+	}
+
+	// Entries are in submission order, so earlier answers win ties
+	sort.SliceStable(results, func(i, j int) bool {
+		ri, rj := results[i].result, results[j].result
+		if ri == nil || rj == nil {
+			return rj == nil && ri != nil
+		}
+		return ri.Score > rj.Score
+	})
+
+	_, err = b.session.ChannelMessageSendComplex(c.ChannelID, &discordgo.MessageSend{
+		Embed: b.createChallengeResultsEmbed(question, results),
+		Reference: &discordgo.MessageReference{
+			MessageID: c.MessageID,
+			ChannelID: c.ChannelID,
+			GuildID:   c.GuildID,
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	})
+	if err != nil {
+		log.Printf("Error posting challenge results: %v", err)
+	}
+}
+
+// createChallengeEmbed creates the embed announcing a challenge question
+func (b *Bot) createChallengeEmbed(questionID int64, japanese, theme, difficulty string, endsAt time.Time) *discordgo.MessageEmbed {
+	difficultyLabel := difficultyName(difficulty)
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("🏁 チャレンジ問題 #%d", questionID),
+		Description: fmt.Sprintf("「%s」", japanese),
+		Color:       0xFFA500,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "🎯 テーマ", Value: theme, Inline: true},
+			{Name: "📊 難易度", Value: difficultyLabel, Inline: true},
+			{Name: "⏱️ 締切", Value: fmt.Sprintf("<t:%d:R>", endsAt.Unix()), Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "💡 誰でも「回答する」ボタンか返信で回答できます。締切後にまとめて採点します！",
+		},
+	}
+}
+
+// createChallengeButtons creates the buttons on a challenge question
+func createChallengeButtons() []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{createAnswerButton()},
+		},
+	}
+}
+
+// createChallengeResultsEmbed creates the ranked results of a challenge
+func (b *Bot) createChallengeResultsEmbed(question *db.Question, results []challengeResult) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("🏆 チャレンジ結果 #%d", question.ID),
+		Color: 0xFFD700,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "📝 問題", Value: fmt.Sprintf("「%s」", question.Japanese)},
+		},
+	}
+
+	if len(results) == 0 {
+		embed.Description = "回答はありませんでした。次回はぜひ挑戦してください！"
+		return embed
+	}
+
+	var lines []string
+	for n, r := range results {
+		if r.result == nil {
+			lines = append(lines, fmt.Sprintf("⚠️ <@%s> 採点失敗 — %s", r.entry.DiscordID, truncate(r.entry.Answer, 100)))
+			continue
+		}
+
+		rank := fmt.Sprintf("**%d.**", n+1)
+		switch n {
+		case 0:
+			rank = "🥇"
+		case 1:
+			rank = "🥈"
+		case 2:
+			rank = "🥉"
+		}
+		lines = append(lines, fmt.Sprintf("%s <@%s> **%d** 点 — %s", rank, r.entry.DiscordID, r.result.Score, truncate(r.entry.Answer, 100)))
+	}
+	embed.Description = truncate(strings.Join(lines, "\n"), 4096)
+
+	// Graded entries come first, so the winner's model answer is shown
+	if results[0].result != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "📖 模範解答",
+			Value: truncate(results[0].result.ModelAnswer, 1024),
+		})
+	}
+	return embed
+}
@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// failingModel is a fake model that fails to evaluate one answer
+type failingModel struct {
+	correctingModel
+	fail string
+}
+
+func (m failingModel) EvaluateAnswer(ctx context.Context, sentence, userAnswer string, direction claude.Direction) (*claude.EvaluationResult, error) {
+	if userAnswer == m.fail {
+		return nil, errors.New("model unavailable")
+	}
+	return m.correctingModel.EvaluateAnswer(ctx, sentence, userAnswer, direction)
+}
+
+func TestChallenge(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("challenge", &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "minutes", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(5),
+	}))
+	post := session.lastMessage()
+	if post == nil || len(post.Embeds) == 0 || !strings.Contains(post.Embeds[0].Title, "チャレンジ問題") {
+		t.Fatalf("Expected challenge embed, got %+v", post)
+	}
+
+	challenge, err := b.db.GetChallenge(post.ID)
+	if err != nil || challenge == nil {
+		t.Fatalf("Expected challenge to be saved, got %v (%v)", challenge, err)
+	}
+
+	// Replies are collected without immediate evaluation
+	answers := map[string]string{
+		"alice": "I drink coffee",
+		"bob":   "I drink coffee every morning.",
+	}
+	for _, id := range []string{"alice", "bob"} {
+		reply := replyTo(post, answers[id])
+		reply.ID = "reply-" + id
+		reply.Author = &discordgo.User{ID: id}
+		b.onMessageCreate(nil, reply)
+	}
+	if len(session.sent) != 0 {
+		t.Fatalf("Expected no evaluations before the challenge closes, got %+v", session.sent)
+	}
+	if len(session.reactions) != 2 || session.reactions[0].Emoji != "✅" {
+		t.Errorf("Expected entries to be acknowledged, got %+v", session.reactions)
+	}
+
+	b.closeChallenge(challenge)
+	b.closeChallenge(challenge)
+
+	if len(session.sent) != 1 {
+		t.Fatalf("Expected one results message, got %+v", session.sent)
+	}
+	results := session.sent[0].Embeds[0]
+	lines := strings.Split(results.Description, "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "🥇 <@bob> **100**") || !strings.HasPrefix(lines[1], "🥈 <@alice> **60**") {
+		t.Errorf("Expected ranked results, got %q", results.Description)
+	}
+
+	for id, want := range map[string]int{"alice": 60, "bob": 100} {
+		stats, _ := b.db.GetUserStats(id, testGuildID, db.ScopeServer)
+		if stats.TotalAnswers != 1 || stats.HighestScore != want {
+			t.Errorf("Expected %s's answer saved with score %d, got %+v", id, want, stats)
+		}
+	}
+
+	// Replies after the challenge closed are turned away
+	late := replyTo(post, "Too late")
+	late.Author = &discordgo.User{ID: "carol"}
+	b.onMessageCreate(nil, late)
+	if msg := session.lastMessage(); !strings.Contains(msg.Content, "締め切られました") {
+		t.Errorf("Expected closed notice, got %q", msg.Content)
+	}
+}
+
+func TestChallenge_NoEntries(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("challenge"))
+	challenge, _ := b.db.GetChallenge(session.lastMessage().ID)
+
+	b.closeChallenge(challenge)
+
+	if len(session.sent) != 1 || !strings.Contains(session.sent[0].Embeds[0].Description, "回答はありませんでした") {
+		t.Errorf("Expected empty results, got %+v", session.sent)
+	}
+}
+
+func TestChallenge_FailedEntry(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("challenge"))
+	post := session.lastMessage()
+	challenge, _ := b.db.GetChallenge(post.ID)
+
+	for id, answer := range map[string]string{"alice": "I drink coffee every morning.", "bob": "???"} {
+		b.acceptChallengeEntry(challenge, id, answer)
+	}
+	b.model = failingModel{correctingModel{claude.NewFake()}, "???"}
+	b.closeChallenge(challenge)
+
+	// Entries that fail to grade are listed instead of dropped
+	lines := strings.Split(session.sent[0].Embeds[0].Description, "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "🥇 <@alice>") || !strings.HasPrefix(lines[1], "⚠️ <@bob> 採点失敗") {
+		t.Errorf("Expected the failed entry listed last, got %q", lines)
+	}
+
+	// Graded entries go through the same pipeline as other answers
+	if entries, _ := b.db.GetVocabulary("alice"); len(entries) != 1 || entries[0].Phrase != "drink" {
+		t.Errorf("Expected the vocabulary correction collected, got %+v", entries)
+	}
+}
@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// difficultyLabels names each difficulty and CEFR level
+var difficultyLabels = map[string]string{
+	"beginner":        "初級",
+	"intermediate":    "中級",
+	"advanced":        "上級",
+	db.DifficultyAuto: "自動",
+	"A1":              "A1 入門",
+	"A2":              "A2 初級",
+	"B1":              "B1 中級",
+	"B2":              "B2 中上級",
+	"C1":              "C1 上級",
+	"C2":              "C2 熟達",
+}
+
+// levelHistorySize is the number of level changes shown in the settings
+const levelHistorySize = 3
+
+// difficultyName names a difficulty or CEFR level
+func difficultyName(difficulty string) string {
+	if label, ok := difficultyLabels[difficulty]; ok {
+		return label
+	}
+	return difficulty
+}
+
+// userDifficultyName names a user's difficulty setting, with their current
+// level in automatic mode
+func userDifficultyName(user *db.User) string {
+	if user.Difficulty == db.DifficultyAuto && user.Level != "" {
+		return fmt.Sprintf("自動（現在 %s）", difficultyName(user.Level))
+	}
+	return difficultyName(user.Difficulty)
+}
+
+// levelHistoryField lists a user's recent level changes, or returns nil if
+// they never used automatic difficulty
+func (b *Bot) levelHistoryField(user *db.User) *discordgo.MessageEmbedField {
+	history, err := b.db.GetLevelHistory(user.DiscordID, user.GuildID, levelHistorySize)
+	if err != nil {
+		log.Printf("Error getting level history: %v", err)
+		return nil
+	}
+	if len(history) == 0 {
+		return nil
+	}
+
+	var lines []string
+	for _, c := range history {
+		date := c.ChangedAt.Format("01/02")
+		if c.PreviousLevel == "" {
+			lines = append(lines, fmt.Sprintf("%s %s から開始", date, c.Level))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %s → %s（平均 %.0f 点）", date, c.PreviousLevel, c.Level, c.AverageScore))
+	}
+	return &discordgo.MessageEmbedField{Name: "📶 レベル履歴", Value: strings.Join(lines, "\n")}
+}
+
+// levelChangeField announces a change of level on an evaluation
+func levelChangeField(change *db.LevelChange) *discordgo.MessageEmbedField {
+	if slices.Index(db.CEFRLevels, change.Level) > slices.Index(db.CEFRLevels, change.PreviousLevel) {
+		return &discordgo.MessageEmbedField{
+			Name:  "🆙 レベルアップ！",
+			Value: fmt.Sprintf("直近 %d 問の平均 %.0f 点で %s → **%s** に上がりました", db.LevelWindow, change.AverageScore, change.PreviousLevel, difficultyName(change.Level)),
+		}
+	}
+	return &discordgo.MessageEmbedField{
+		Name:  "📶 レベル調整",
+		Value: fmt.Sprintf("直近 %d 問の平均 %.0f 点のため %s → **%s** で練習しましょう", db.LevelWindow, change.AverageScore, change.PreviousLevel, difficultyName(change.Level)),
+	}
+}
@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+func TestAutoDifficulty_LevelsUp(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, componentClick("difficulty_select", "advanced"))
+	b.onInteractionCreate(nil, componentClick("difficulty_select", db.DifficultyAuto))
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "C1 上級") {
+		t.Fatalf("Expected to start at C1, got %q", resp.Data.Content)
+	}
+
+	for n := 0; n < db.LevelWindow; n++ {
+		b.onInteractionCreate(nil, slashCommand("quiz"))
+		quiz := session.lastMessage()
+		if quiz.Embeds[0].Fields[1].Value != "C1 上級" {
+			t.Fatalf("Expected a C1 quiz, got %q", quiz.Embeds[0].Fields[1].Value)
+		}
+
+		japanese := strings.Trim(quiz.Embeds[0].Description, "「」")
+		answer, _ := b.model.GenerateModelAnswer(context.Background(), japanese, claude.JapaneseToEnglish)
+		session.sent = nil
+		b.onMessageCreate(nil, replyTo(quiz, answer))
+	}
+
+	var levelUp bool
+	for _, f := range session.sent[0].Embeds[0].Fields {
+		levelUp = levelUp || f.Name == "🆙 レベルアップ！"
+	}
+	if !levelUp {
+		t.Errorf("Expected a level up on the fifth perfect answer, got %+v", session.sent[0].Embeds[0].Fields)
+	}
+
+	user, _ := b.db.GetOrCreateUser(testUserID, testGuildID)
+	if user.Level != "C2" || user.QuestionDifficulty() != "C2" {
+		t.Errorf("Expected level C2, got %q", user.Level)
+	}
+
+	b.onInteractionCreate(nil, slashCommand("settings"))
+	embed := session.lastResponse().Data.Embeds[0]
+	if embed.Fields[0].Value != "自動（現在 C2 熟達）" {
+		t.Errorf("Expected the current level in the settings, got %q", embed.Fields[0].Value)
+	}
+	if history := embed.Fields[len(embed.Fields)-1]; !strings.Contains(history.Value, "C1 → C2") {
+		t.Errorf("Expected the level history in the settings, got %+v", history)
+	}
+}
@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"math/rand/v2"
+
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// directionLabels are the settings labels of each translation direction
+var directionLabels = map[string]string{
+	db.DirectionJaEn:  "日本語→英語",
+	db.DirectionEnJa:  "英語→日本語",
+	db.DirectionMixed: "ミックス",
+}
+
+// pickDirection resolves a user's direction setting to the direction of one
+// question; mixed settings pick either direction at random
+func pickDirection(setting string) string {
+	switch setting {
+	case db.DirectionEnJa:
+		return db.DirectionEnJa
+	case db.DirectionMixed:
+		if rand.IntN(2) == 0 {
+			return db.DirectionEnJa
+		}
+	}
+	return db.DirectionJaEn
+}
+
+// quizKind names the kind of exercise a question in the direction is
+func quizKind(direction string) string {
+	if direction == db.DirectionEnJa {
+		return "和訳問題"
+	}
+	return "英作文問題"
+}
+
+// answerLabel names the answer the learner writes for the direction
+func answerLabel(direction string) string {
+	if direction == db.DirectionEnJa {
+		return "和訳"
+	}
+	return "英訳"
+}
@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// exerciseKinds names each exercise type other than translation
+var exerciseKinds = map[string]string{
+	string(claude.ExerciseCloze):      "穴埋め問題",
+	string(claude.ExerciseChoice):     "4択問題",
+	string(claude.ExerciseReorder):    "並べ替え問題",
+	string(claude.ExerciseCorrection): "誤り訂正問題",
+	string(claude.ExerciseVocab):      "単語帳クイズ",
+}
+
+// exerciseInstructions tells the learner how to answer each exercise type
+var exerciseInstructions = map[string]string{
+	string(claude.ExerciseCloze):      "空欄（___）に入る語を答えてください",
+	string(claude.ExerciseChoice):     "空欄に入るものを下のメニューから選んでください",
+	string(claude.ExerciseReorder):    "単語を並べ替えて正しい英文を作ってください",
+	string(claude.ExerciseCorrection): "誤りを1つ見つけて、正しい英文に直してください",
+	string(claude.ExerciseVocab):      "この意味を表す英語の語句を答えてください",
+}
+
+// exerciseAnswerLabels names the answer the learner writes for each exercise type
+var exerciseAnswerLabels = map[string]string{
+	string(claude.ExerciseCloze):      "空欄に入る語",
+	string(claude.ExerciseChoice):     "選んだ答え",
+	string(claude.ExerciseReorder):    "並べ替えた英文",
+	string(claude.ExerciseCorrection): "訂正した英文",
+	string(claude.ExerciseVocab):      "英語の語句",
+}
+
+// questionKind names the kind of exercise a question is
+func questionKind(q *db.Question) string {
+	if kind, ok := exerciseKinds[q.ExerciseType]; ok {
+		return kind
+	}
+	return quizKind(q.Direction)
+}
+
+// questionAnswerLabel names the answer the learner writes for a question
+func questionAnswerLabel(q *db.Question) string {
+	if label, ok := exerciseAnswerLabels[q.ExerciseType]; ok {
+		return label
+	}
+	return answerLabel(q.Direction)
+}
+
+// exerciseFromQuestion rebuilds the exercise a question was saved from
+func exerciseFromQuestion(q *db.Question) *claude.Exercise {
+	return &claude.Exercise{
+		Type:       claude.ExerciseType(q.ExerciseType),
+		Prompt:     q.Japanese,
+		Context:    q.Context,
+		Choices:    q.Choices,
+		Answer:     q.AnswerKey,
+		Alternates: q.Alternates,
+	}
+}
+
+// sendNewExercise generates an exercise of the given type from the user's
+// settings and delivers it in response to an interaction
+func (b *Bot) sendNewExercise(i *discordgo.InteractionCreate, exerciseType claude.ExerciseType) {
+	userID := interactionUserID(i)
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		b.respondComponentMessage(i, "エラーが発生しました")
+		return
+	}
+
+	private := deliversPrivately(i, user)
+	b.deferResponse(i, private)
+
+	ctx := context.Background()
+	exercise, err := b.model.GenerateExercise(ctx, exerciseType, user.Theme, user.QuestionDifficulty())
+	if err != nil {
+		log.Printf("Error generating exercise: %v", err)
+		b.respondError(i, "問題の生成に失敗しました")
+		return
+	}
+
+	question := &db.Question{
+		GuildID:      i.GuildID,
+		Japanese:     exercise.Prompt,
+		Difficulty:   user.QuestionDifficulty(),
+		Theme:        user.Theme,
+		ExerciseType: string(exercise.Type),
+		Context:      exercise.Context,
+		Choices:      exercise.Choices,
+		AnswerKey:    exercise.Answer,
+		Alternates:   exercise.Alternates,
+	}
+	question.ID, err = b.db.SaveExercise(question)
+	if err != nil {
+		log.Printf("Error saving exercise: %v", err)
+		b.respondError(i, "問題の保存に失敗しました")
+		return
+	}
+
+	b.sendQuiz(i, private, question.ID, "", b.createExerciseEmbed(question), b.createExerciseComponents(question))
+}
+
+// handleChoiceSelect evaluates the option picked on a multiple choice exercise
+func (b *Bot) handleChoiceSelect(i *discordgo.InteractionCreate) {
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return
+	}
+
+	quiz := b.quizForClick(i)
+	if quiz == nil {
+		return
+	}
+
+	b.respondWithEvaluation(i, quiz, values[0])
+}
+
+// createExerciseEmbed creates the embed of an exercise other than translation
+func (b *Bot) createExerciseEmbed(q *db.Question) *discordgo.MessageEmbed {
+	difficultyLabel := difficultyName(q.Difficulty)
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "🎯 テーマ", Value: q.Theme, Inline: true},
+		{Name: "📊 難易度", Value: difficultyLabel, Inline: true},
+	}
+	if q.Context != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "🇯🇵 意味", Value: q.Context})
+	}
+
+	footer := "💡 「回答する」ボタンか、このメッセージへの返信で回答してください！"
+	if q.ExerciseType == string(claude.ExerciseChoice) {
+		footer = "💡 メニューから選ぶか、このメッセージへの返信で回答してください！"
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("📝 %s #%d", questionKind(q), q.ID),
+		Description: fmt.Sprintf("**%s**\n\n%s", q.Japanese, exerciseInstructions[q.ExerciseType]),
+		Color:       0x5865F2,
+		Fields:      fields,
+		Footer:      &discordgo.MessageEmbedFooter{Text: footer},
+	}
+}
+
+// createExerciseComponents creates the components of an exercise message.
+// Multiple choice exercises are answered from a select menu; hints and
+// reveals are only offered for translation questions.
+func (b *Bot) createExerciseComponents(q *db.Question) []discordgo.MessageComponent {
+	navigation := []discordgo.MessageComponent{
+		discordgo.Button{
+			Label:    "📝 次の問題",
+			Style:    discordgo.PrimaryButton,
+			CustomID: "quiz_next",
+		},
+		discordgo.Button{
+			Label:    "⚙️ 設定",
+			Style:    discordgo.SecondaryButton,
+			CustomID: "settings_open",
+		},
+		discordgo.Button{
+			Label:    "📊 統計",
+			Style:    discordgo.SecondaryButton,
+			CustomID: "stats_show",
+		},
+	}
+
+	if q.ExerciseType != string(claude.ExerciseChoice) {
+		return []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: append([]discordgo.MessageComponent{createAnswerButton()}, navigation...)},
+		}
+	}
+
+	options := make([]discordgo.SelectMenuOption, len(q.Choices))
+	for n, choice := range q.Choices {
+		options[n] = discordgo.SelectMenuOption{Label: choice, Value: choice}
+	}
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    "choice_select",
+					Placeholder: "答えを選択",
+					Options:     options,
+				},
+			},
+		},
+		discordgo.ActionsRow{Components: navigation},
+	}
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// offlineModel is a fake model whose exercise grader is unavailable
+type offlineModel struct {
+	*claude.Fake
+}
+
+func (offlineModel) EvaluateExercise(ctx context.Context, exercise *claude.Exercise, userAnswer string) (*claude.EvaluationResult, error) {
+	return nil, errors.New("model unavailable")
+}
+
+// quizOfType builds a /quiz command for an exercise type
+func quizOfType(exerciseType string) *discordgo.InteractionCreate {
+	return slashCommand("quiz", &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  "type",
+		Type:  discordgo.ApplicationCommandOptionString,
+		Value: exerciseType,
+	})
+}
+
+func TestExercise_Cloze(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, quizOfType("cloze"))
+
+	quiz := session.lastMessage()
+	embed := quiz.Embeds[0]
+	if !strings.Contains(embed.Title, "穴埋め問題") || !strings.Contains(embed.Description, "I ___ coffee every morning.") {
+		t.Fatalf("Expected a cloze exercise, got %+v", embed)
+	}
+
+	b.onMessageCreate(nil, replyTo(quiz, "drink"))
+
+	stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+	if stats.TotalAnswers != 1 || stats.HighestScore != 100 {
+		t.Errorf("Expected the reply graded by the cloze grader, got %+v", stats)
+	}
+}
+
+func TestExercise_Choice(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, quizOfType("choice"))
+
+	quiz := session.lastMessage()
+	menu := quiz.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	if menu.CustomID != "choice_select" || len(menu.Options) != 4 {
+		t.Fatalf("Expected a select menu with 4 choices, got %+v", menu)
+	}
+
+	click := clickOn(quiz, "choice_select")
+	click.Data = discordgo.MessageComponentInteractionData{CustomID: "choice_select", Values: []string{"drink"}}
+	b.onInteractionCreate(nil, click)
+
+	edit := session.lastEdit()
+	if edit == nil || edit.Embeds == nil || !strings.Contains((*edit.Embeds)[0].Title, "回答評価") {
+		t.Fatalf("Expected an evaluation, got %+v", edit)
+	}
+	if len(session.sent) != 0 {
+		t.Errorf("Expected the evaluation to be ephemeral, got %+v", session.sent)
+	}
+
+	stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+	if stats.HighestScore != 100 {
+		t.Errorf("Expected the correct choice to score 100, got %+v", stats)
+	}
+}
+
+func TestExercise_GradedWithoutModel(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, quizOfType("reorder"))
+	quiz := session.lastMessage()
+	b.model = offlineModel{claude.NewFake()}
+
+	for _, answer := range []string{"i coffee drink every morning", "i drink coffee every morning"} {
+		b.onMessageCreate(nil, replyTo(quiz, answer))
+	}
+
+	last, _ := b.db.GetLastAnswer(testUserID, 1)
+	if last == nil || last.Attempt != 2 || last.Score != 100 || last.ModelAnswer != "I drink coffee every morning." {
+		t.Errorf("Expected both answers graded from the answer key, got %+v", last)
+	}
+}
+
+func TestExercise_CorrectionModal(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, quizOfType("correction"))
+	quiz := session.lastMessage()
+
+	b.onInteractionCreate(nil, clickOn(quiz, "answer_open"))
+
+	resp := session.lastResponse()
+	input := resp.Data.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.TextInput)
+	if !strings.Contains(resp.Data.Title, "誤り訂正問題") || input.Label != "訂正した英文" {
+		t.Errorf("Expected a correction answer modal, got %q with %+v", resp.Data.Title, input)
+	}
+}
@@ -0,0 +1,210 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// flashcardPrefix starts the custom ID of flashcard buttons, which carry the
+// session as "flashcard:<action>:<entry>:<knew>:<seen>"
+const flashcardPrefix = "flashcard:"
+
+// Flashcard button actions
+const (
+	flashcardFlip   = "flip"
+	flashcardKnew   = "knew"
+	flashcardForgot = "forgot"
+)
+
+// flashcardsCommand is the /flashcards command
+var flashcardsCommand = &discordgo.ApplicationCommand{
+	Name:        "flashcards",
+	Description: "Drill the phrases in your vocabulary notebook that are due",
+}
+
+// flashcardView is one step of a flashcard session: the card being shown and
+// how many cards the user has rated so far
+type flashcardView struct {
+	entryID int64
+	knew    int
+	seen    int
+}
+
+// customID returns the custom ID of a button that applies the action to the view
+func (v flashcardView) customID(action string) string {
+	return fmt.Sprintf("%s%s:%d:%d:%d", flashcardPrefix, action, v.entryID, v.knew, v.seen)
+}
+
+// parseFlashcardButton parses a flashcard button's custom ID
+func parseFlashcardButton(customID string) (string, flashcardView, bool) {
+	parts := strings.Split(strings.TrimPrefix(customID, flashcardPrefix), ":")
+	if len(parts) != 4 {
+		return "", flashcardView{}, false
+	}
+	var numbers [3]int64
+	for n, part := range parts[1:] {
+		value, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || value < 0 {
+			return "", flashcardView{}, false
+		}
+		numbers[n] = value
+	}
+	return parts[0], flashcardView{entryID: numbers[0], knew: int(numbers[1]), seen: int(numbers[2])}, true
+}
+
+// handleFlashcardsCommand starts a flashcard session with the user's next due card
+func (b *Bot) handleFlashcardsCommand(i *discordgo.InteractionCreate) {
+	entry, err := b.db.GetNextFlashcard(interactionUserID(i))
+	if err != nil {
+		log.Printf("Error getting flashcard: %v", err)
+		b.respondComponentMessage(i, "フラッシュカードの取得に失敗しました")
+		return
+	}
+	if entry == nil {
+		b.respondComponentMessage(i, "🃏 今日のカードはありません。/vocab add や回答評価の「📌 単語帳に追加」で単語帳に語句を追加しましょう")
+		return
+	}
+
+	embed, components := b.createFlashcardFront(entry, flashcardView{entryID: entry.ID})
+	b.respondFlashcard(i, discordgo.InteractionResponseChannelMessageWithSource, embed, components)
+}
+
+// handleFlashcardButton flips the card or records the rating and moves on to
+// the next due card
+func (b *Bot) handleFlashcardButton(i *discordgo.InteractionCreate, customID string) {
+	action, view, ok := parseFlashcardButton(customID)
+	if !ok {
+		b.respondComponentMessage(i, "エラーが発生しました")
+		return
+	}
+	userID := interactionUserID(i)
+
+	switch action {
+	case flashcardFlip:
+		entry, err := b.db.GetVocabularyEntry(userID, view.entryID)
+		if err != nil || entry == nil {
+			b.respondComponentMessage(i, "❌ このカードは見つかりませんでした")
+			return
+		}
+		embed, components := b.createFlashcardBack(entry, view)
+		b.respondFlashcard(i, discordgo.InteractionResponseUpdateMessage, embed, components)
+
+	case flashcardKnew, flashcardForgot:
+		if err := b.db.RecordFlashcard(userID, view.entryID, action == flashcardKnew); err != nil {
+			log.Printf("Error recording flashcard: %v", err)
+			b.respondComponentMessage(i, "❌ このカードは見つかりませんでした")
+			return
+		}
+		view.seen++
+		if action == flashcardKnew {
+			view.knew++
+		}
+
+		entry, err := b.db.GetNextFlashcard(userID)
+		if err != nil {
+			log.Printf("Error getting flashcard: %v", err)
+			b.respondComponentMessage(i, "フラッシュカードの取得に失敗しました")
+			return
+		}
+		if entry == nil {
+			b.respondFlashcard(i, discordgo.InteractionResponseUpdateMessage, b.createFlashcardSummary(view), nil)
+			return
+		}
+		view.entryID = entry.ID
+		embed, components := b.createFlashcardFront(entry, view)
+		b.respondFlashcard(i, discordgo.InteractionResponseUpdateMessage, embed, components)
+	}
+}
+
+// respondFlashcard shows a flashcard step to the user as a new message or an update
+func (b *Bot) respondFlashcard(i *discordgo.InteractionCreate, responseType discordgo.InteractionResponseType, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) {
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: responseType,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// createFlashcardFront creates the front of a card, showing only the phrase
+func (b *Bot) createFlashcardFront(entry *db.VocabularyEntry, view flashcardView) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	due, err := b.db.CountDueFlashcards(entry.DiscordID)
+	if err != nil {
+		log.Printf("Error counting flashcards: %v", err)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "🃏 フラッシュカード",
+		Description: fmt.Sprintf("**%s**\n\n意味を思い浮かべてから裏返してください", entry.Phrase),
+		Color:       0x5865F2,
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("残り %d 枚", due)},
+	}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "🔄 裏返す",
+					Style:    discordgo.PrimaryButton,
+					CustomID: view.customID(flashcardFlip),
+				},
+			},
+		},
+	}
+	return embed, components
+}
+
+// createFlashcardBack creates the back of a card with the rating buttons
+func (b *Bot) createFlashcardBack(entry *db.VocabularyEntry, view flashcardView) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	meaning := entry.Meaning
+	if meaning == "" {
+		meaning = "（意味は登録されていません）"
+	}
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "🇯🇵 意味", Value: meaning},
+	}
+	if entry.Note != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "📝 メモ", Value: truncate(entry.Note, 1024)})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "🃏 フラッシュカード",
+		Description: fmt.Sprintf("**%s**", entry.Phrase),
+		Color:       0x00D4AA,
+		Fields:      fields,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "覚えていましたか？"},
+	}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "⭕ 覚えていた",
+					Style:    discordgo.SuccessButton,
+					CustomID: view.customID(flashcardKnew),
+				},
+				discordgo.Button{
+					Label:    "❌ 覚えていなかった",
+					Style:    discordgo.DangerButton,
+					CustomID: view.customID(flashcardForgot),
+				},
+			},
+		},
+	}
+	return embed, components
+}
+
+// createFlashcardSummary creates the embed shown when no cards are left
+func (b *Bot) createFlashcardSummary(view flashcardView) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       "🎉 フラッシュカード完了",
+		Description: fmt.Sprintf("今日のカードはすべて終わりました！\n覚えていた: **%d** / %d 枚", view.knew, view.seen),
+		Color:       0xFFD700,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "覚えていなかったカードは明日また出題されます"},
+	}
+}
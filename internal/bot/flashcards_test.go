@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// flashcardButton returns the button of a flashcard response at the given position
+func flashcardButton(resp *discordgo.InteractionResponse, n int) discordgo.Button {
+	return resp.Data.Components[0].(discordgo.ActionsRow).Components[n].(discordgo.Button)
+}
+
+func TestFlashcards(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("flashcards"))
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "カードはありません") {
+		t.Fatalf("Expected no cards for an empty notebook, got %+v", resp.Data)
+	}
+
+	b.db.AddVocabulary(&db.VocabularyEntry{DiscordID: testUserID, Phrase: "every morning", Meaning: "毎朝", Source: db.VocabManual})
+	b.db.AddVocabulary(&db.VocabularyEntry{DiscordID: testUserID, Phrase: "the way to", Meaning: "〜への道", Source: db.VocabManual})
+
+	b.onInteractionCreate(nil, slashCommand("flashcards"))
+	resp := session.lastResponse()
+	embed := resp.Data.Embeds[0]
+	if resp.Data.Flags != discordgo.MessageFlagsEphemeral || !strings.Contains(embed.Description, "every morning") || strings.Contains(embed.Description, "毎朝") {
+		t.Fatalf("Expected the front of the first card, got %+v", embed)
+	}
+	if embed.Footer.Text != "残り 2 枚" {
+		t.Errorf("Expected 2 cards left, got %q", embed.Footer.Text)
+	}
+
+	// Flip, rate as known, then the next card
+	b.onInteractionCreate(nil, componentClick(flashcardButton(resp, 0).CustomID))
+	resp = session.lastResponse()
+	if resp.Type != discordgo.InteractionResponseUpdateMessage || resp.Data.Embeds[0].Fields[0].Value != "毎朝" {
+		t.Fatalf("Expected the back of the card, got %+v", resp.Data.Embeds[0])
+	}
+
+	b.onInteractionCreate(nil, componentClick(flashcardButton(resp, 0).CustomID))
+	resp = session.lastResponse()
+	if embed := resp.Data.Embeds[0]; !strings.Contains(embed.Description, "the way to") || embed.Footer.Text != "残り 1 枚" {
+		t.Fatalf("Expected the second card, got %+v", embed)
+	}
+
+	// Flip and rate as not known; no cards are left
+	b.onInteractionCreate(nil, componentClick(flashcardButton(resp, 0).CustomID))
+	resp = session.lastResponse()
+	b.onInteractionCreate(nil, componentClick(flashcardButton(resp, 1).CustomID))
+
+	resp = session.lastResponse()
+	if embed := resp.Data.Embeds[0]; !strings.Contains(embed.Description, "**1** / 2") || len(resp.Data.Components) != 0 {
+		t.Errorf("Expected a summary of 1 known card out of 2, got %+v", embed)
+	}
+	if count, _ := b.db.CountDueFlashcards(testUserID); count != 0 {
+		t.Errorf("Expected both cards rescheduled, got %d due", count)
+	}
+}
+
+func TestFlashcards_OtherUsersCard(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.db.AddVocabulary(&db.VocabularyEntry{DiscordID: "someone-else", Phrase: "secret", Source: db.VocabManual})
+
+	b.onInteractionCreate(nil, componentClick(flashcardView{entryID: 1}.customID(flashcardKnew)))
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "見つかりません") {
+		t.Errorf("Expected another user's card to be refused, got %+v", resp.Data)
+	}
+	if count, _ := b.db.CountDueFlashcards("someone-else"); count != 1 {
+		t.Errorf("Expected the card to stay due, got %d", count)
+	}
+}
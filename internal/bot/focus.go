@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// Quiz focus modes chosen in /quiz
+const (
+	focusWeaknesses = "weaknesses"
+	focusVocabulary = "vocab"
+)
+
+// weakQuizPrefix starts the custom ID of the /weaknesses button, followed by
+// the scope the report was built with
+const weakQuizPrefix = "quiz_weak:"
+
+// focusSize is the number of weak categories or notebook phrases a focused
+// question targets
+const focusSize = 3
+
+// quizFocus builds the focus of a quiz from the mode and grammar point chosen
+// in /quiz, taking weak spots from answers in the scope. If the mode has
+// nothing to target it tells the user so and returns false.
+func (b *Bot) quizFocus(i *discordgo.InteractionCreate, mode, grammar string, scope db.StatsScope) (claude.Focus, bool) {
+	focus := claude.Focus{Grammar: strings.TrimSpace(grammar)}
+	userID := interactionUserID(i)
+
+	switch mode {
+	case focusWeaknesses:
+		// "other" cannot be drilled, so ask for one more category than needed
+		weaknesses, err := b.db.GetWeaknesses(userID, i.GuildID, scope, focusSize+1)
+		if err != nil {
+			log.Printf("Error getting weaknesses: %v", err)
+			b.respondComponentMessage(i, "苦手分析の取得に失敗しました")
+			return focus, false
+		}
+		for _, w := range weaknesses {
+			if w.Category != "other" && len(focus.Weaknesses) < focusSize {
+				focus.Weaknesses = append(focus.Weaknesses, w.Category)
+			}
+		}
+		if len(focus.Weaknesses) == 0 {
+			b.respondComponentMessage(i, "まだ記録された誤りがありません。まずは /quiz で問題に挑戦しましょう！")
+			return focus, false
+		}
+	case focusVocabulary:
+		entries, err := b.db.GetVocabulary(userID)
+		if err != nil {
+			log.Printf("Error getting vocabulary: %v", err)
+			b.respondComponentMessage(i, "単語帳の取得に失敗しました")
+			return focus, false
+		}
+		for _, e := range entries[:min(len(entries), focusSize)] {
+			focus.Vocabulary = append(focus.Vocabulary, e.Phrase)
+		}
+		if len(focus.Vocabulary) == 0 {
+			b.respondComponentMessage(i, "単語帳が空です。/vocab add で語句を追加しましょう！")
+			return focus, false
+		}
+	}
+	return focus, true
+}
+
+// createFocusField shows what a focused quiz targets
+func createFocusField(focus claude.Focus) *discordgo.MessageEmbedField {
+	var lines []string
+	if len(focus.Weaknesses) > 0 {
+		labels := make([]string, len(focus.Weaknesses))
+		for n, category := range focus.Weaknesses {
+			labels[n] = errorCategoryLabels[category]
+		}
+		lines = append(lines, fmt.Sprintf("苦手: %s", strings.Join(labels, "、")))
+	}
+	if len(focus.Vocabulary) > 0 {
+		lines = append(lines, fmt.Sprintf("語句: %s", strings.Join(focus.Vocabulary, ", ")))
+	}
+	if focus.Grammar != "" {
+		lines = append(lines, fmt.Sprintf("文法: %s", focus.Grammar))
+	}
+	return &discordgo.MessageEmbedField{Name: "🎯 重点", Value: truncate(strings.Join(lines, "\n"), 1024)}
+}
+
+// handleWeakQuizButton starts a quiz targeting the user's weak spots in the
+// scope of the /weaknesses embed
+func (b *Bot) handleWeakQuizButton(i *discordgo.InteractionCreate, scope db.StatsScope) {
+	if scope != db.ScopeGlobal {
+		scope = db.ScopeServer
+	}
+	focus, ok := b.quizFocus(i, focusWeaknesses, "", scope)
+	if !ok {
+		return
+	}
+	b.sendNewQuiz(i, focus)
+}
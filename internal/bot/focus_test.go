@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// focusModel is a fake model that records the focus of the last generated question
+type focusModel struct {
+	*claude.Fake
+	focus *claude.Focus
+}
+
+func (m focusModel) GenerateQuestion(ctx context.Context, theme, difficulty string, direction claude.Direction, focus claude.Focus) (string, error) {
+	*m.focus = focus
+	return m.Fake.GenerateQuestion(ctx, theme, difficulty, direction, focus)
+}
+
+func TestQuizFocus_Weaknesses(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz", stringOption("focus", focusWeaknesses)))
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "まだ記録された誤りがありません") {
+		t.Fatalf("Expected no weak spots to target yet, got %+v", resp.Data)
+	}
+
+	b.model = correctingModel{claude.NewFake()}
+	for range 2 {
+		b.onInteractionCreate(nil, slashCommand("quiz"))
+		b.onMessageCreate(nil, replyTo(session.lastMessage(), "I take coffee."))
+	}
+
+	var focus claude.Focus
+	b.model = focusModel{claude.NewFake(), &focus}
+
+	b.onInteractionCreate(nil, slashCommand("weaknesses"))
+	button := session.lastResponse().Data.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.Button)
+	b.onInteractionCreate(nil, componentClick(button.CustomID))
+
+	if !slices.Equal(focus.Weaknesses, []string{"grammar", "vocabulary"}) {
+		t.Errorf("Expected the question to target the recorded weak spots, got %+v", focus)
+	}
+	fields := session.lastMessage().Embeds[0].Fields
+	if f := fields[len(fields)-1]; f.Name != "🎯 重点" || f.Value != "苦手: その他の文法、語の選択" {
+		t.Errorf("Expected the focus shown on the quiz, got %+v", f)
+	}
+
+	// Weak spots are drilled in English even for users practising en→ja
+	b.db.UpdateUserDirection(testUserID, testGuildID, db.DirectionEnJa)
+	b.onInteractionCreate(nil, slashCommand("quiz", stringOption("focus", focusWeaknesses)))
+	quiz, _ := b.db.GetQuizMessage(session.lastMessage().ID)
+	if question, _ := b.db.GetQuestion(quiz.QuestionID); question.Direction != db.DirectionJaEn {
+		t.Errorf("Expected a ja→en question for weak spots, got %q", question.Direction)
+	}
+}
+
+func TestQuizFocus_WeaknessesScope(t *testing.T) {
+	b, session := newTestBot(t)
+	var focus claude.Focus
+	b.model = focusModel{claude.NewFake(), &focus}
+
+	// The only recorded error comes from another server
+	questionID, _ := b.db.SaveQuestion("other-guild", "昨日公園に行きました。", "beginner", "旅行", db.DirectionJaEn)
+	answerID, _ := b.db.SaveAnswer(testUserID, "other-guild", questionID, "I goed to the park.", "I went to the park yesterday.", 40, "")
+	b.db.SaveAnswerErrors(answerID, []db.AnswerError{{Category: "tense", Span: "goed", Suggestion: "went"}})
+
+	b.onInteractionCreate(nil, slashCommand("weaknesses", stringOption("scope", string(db.ScopeGlobal))))
+	button := session.lastResponse().Data.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.Button)
+	b.onInteractionCreate(nil, componentClick(button.CustomID))
+
+	if !slices.Equal(focus.Weaknesses, []string{"tense"}) {
+		t.Errorf("Expected the question to target the weak spots of the global report, got %+v", focus)
+	}
+}
+
+func TestQuizFocus_VocabularyAndGrammar(t *testing.T) {
+	b, session := newTestBot(t)
+	var focus claude.Focus
+	b.model = focusModel{claude.NewFake(), &focus}
+
+	b.onInteractionCreate(nil, slashCommand("quiz", stringOption("focus", focusVocabulary)))
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "単語帳が空です") {
+		t.Fatalf("Expected an empty notebook to be reported, got %+v", resp.Data)
+	}
+
+	b.db.AddVocabulary(&db.VocabularyEntry{DiscordID: testUserID, Phrase: "look forward to", Meaning: "楽しみにする", Source: db.VocabManual})
+	b.onInteractionCreate(nil, slashCommand("quiz", stringOption("focus", focusVocabulary), stringOption("grammar", " present perfect ")))
+	if !slices.Equal(focus.Vocabulary, []string{"look forward to"}) || focus.Grammar != "present perfect" {
+		t.Errorf("Expected the notebook phrase and grammar point targeted, got %+v", focus)
+	}
+
+	b.onInteractionCreate(nil, slashCommand("quiz", stringOption("type", string(claude.ExerciseCloze)), stringOption("grammar", "passive")))
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "翻訳問題でのみ") {
+		t.Errorf("Expected a focus on other exercises to be refused, got %+v", resp.Data)
+	}
+}
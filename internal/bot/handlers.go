@@ -6,120 +6,222 @@ import (
 	"log"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
 )
 
 // onInteractionCreate handles slash commands and button interactions
-func (b *Bot) onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *Bot) onInteractionCreate(_ *discordgo.Session, i *discordgo.InteractionCreate) {
 	switch i.Type {
 	case discordgo.InteractionApplicationCommand:
-		b.handleSlashCommand(s, i)
+		b.handleSlashCommand(i)
 	case discordgo.InteractionMessageComponent:
-		b.handleComponentInteraction(s, i)
+		b.handleComponentInteraction(i)
 	case discordgo.InteractionModalSubmit:
-		b.handleModalSubmit(s, i)
+		b.handleModalSubmit(i)
 	}
 }
 
 // handleSlashCommand handles slash command interactions
-func (b *Bot) handleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *Bot) handleSlashCommand(i *discordgo.InteractionCreate) {
 	switch i.ApplicationCommandData().Name {
 	case "quiz":
-		b.handleQuizCommand(s, i)
+		b.handleQuizCommand(i)
 	case "theme":
-		b.handleThemeCommand(s, i)
+		b.handleThemeCommand(i)
 	case "stats":
-		b.handleStatsCommand(s, i)
+		b.handleStatsCommand(i)
 	case "settings":
-		b.handleSettingsCommand(s, i)
+		b.handleSettingsCommand(i)
+	case "review":
+		b.handleReviewCommand(i)
+	case "schedule":
+		b.handleScheduleModalButton(i)
+	case "setup":
+		b.handleSetupCommand(i)
+	case "leaderboard":
+		b.handleLeaderboardCommand(i)
+	case "challenge":
+		b.handleChallengeCommand(i)
+	case "vocab":
+		b.handleVocabCommand(i)
+	case "flashcards":
+		b.handleFlashcardsCommand(i)
+	case "weaknesses":
+		b.handleWeaknessesCommand(i)
 	}
 }
 
-// handleQuizCommand generates and sends a new quiz question
-func (b *Bot) handleQuizCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	// Defer response to avoid timeout
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
-	})
+// handleQuizCommand generates and sends a new quiz question, optionally
+// focused on the user's weak spots, notebook phrases or a grammar point, or an
+// exercise of the type chosen in the command
+func (b *Bot) handleQuizCommand(i *discordgo.InteractionCreate) {
+	exerciseType := db.ExerciseTranslation
+	var mode, grammar string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "type":
+			exerciseType = opt.StringValue()
+		case "focus":
+			mode = opt.StringValue()
+		case "grammar":
+			grammar = opt.StringValue()
+		}
+	}
 
-	userID := i.Member.User.ID
-	user, err := b.db.GetOrCreateUser(userID)
+	if exerciseType != db.ExerciseTranslation {
+		if mode != "" || grammar != "" {
+			b.respondComponentMessage(i, "🎯 重点の指定は翻訳問題でのみ使えます")
+			return
+		}
+		b.sendNewExercise(i, claude.ExerciseType(exerciseType))
+		return
+	}
+
+	focus, ok := b.quizFocus(i, mode, grammar, db.ScopeServer)
+	if !ok {
+		return
+	}
+	b.sendNewQuiz(i, focus)
+}
+
+// sendNewQuiz generates a question from the user's settings and the focus and
+// delivers it in response to an interaction. Questions targeting weak spots
+// are always translated into English.
+func (b *Bot) sendNewQuiz(i *discordgo.InteractionCreate, focus claude.Focus) {
+	userID := interactionUserID(i)
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
 	if err != nil {
 		log.Printf("Error getting user: %v", err)
-		b.respondError(s, i, "エラーが発生しました")
+		b.respondComponentMessage(i, "エラーが発生しました")
 		return
 	}
 
-	// Generate question using Claude
+	// Defer response to avoid timeout
+	private := deliversPrivately(i, user)
+	b.deferResponse(i, private)
+
+	// Generate question using the quiz model
 	ctx := context.Background()
-	japanese, err := b.claude.GenerateQuestion(ctx, user.Theme, user.Difficulty)
+	direction := pickDirection(user.Direction)
+	if len(focus.Weaknesses) > 0 {
+		// Weak spots are errors in English answers, so they need an English answer
+		direction = db.DirectionJaEn
+	}
+	japanese, err := b.model.GenerateQuestion(ctx, user.Theme, user.QuestionDifficulty(), claude.Direction(direction), focus)
 	if err != nil {
 		log.Printf("Error generating question: %v", err)
-		b.respondError(s, i, "問題の生成に失敗しました")
+		b.respondError(i, "問題の生成に失敗しました")
 		return
 	}
 
 	// Save question to database
-	questionID, err := b.db.SaveQuestion(japanese, user.Difficulty, user.Theme)
+	questionID, err := b.db.SaveQuestion(i.GuildID, japanese, user.QuestionDifficulty(), user.Theme, direction)
 	if err != nil {
 		log.Printf("Error saving question: %v", err)
 	}
 
 	// Create quiz message with buttons
-	embed := b.createQuizEmbed(questionID, japanese, user.Theme, user.Difficulty)
-	components := b.createQuizButtons()
+	embed := b.createQuizEmbed(questionID, japanese, user.Theme, user.QuestionDifficulty(), direction)
+	if !focus.IsZero() {
+		embed.Fields = append(embed.Fields, createFocusField(focus))
+	}
+	b.sendQuiz(i, private, questionID, "", embed, b.createQuizButtons())
+}
 
-	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Embeds:     &[]*discordgo.MessageEmbed{embed},
-		Components: &components,
-	})
+// handleReviewCommand sends the next question asked in this guild that is due
+// for spaced-repetition review
+func (b *Bot) handleReviewCommand(i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
 	if err != nil {
-		log.Printf("Error sending quiz: %v", err)
+		log.Printf("Error getting user: %v", err)
+		b.respondMessage(i, "エラーが発生しました")
+		return
+	}
+
+	private := deliversPrivately(i, user)
+	b.deferResponse(i, private)
+
+	review, err := b.db.GetNextDueReview(userID, i.GuildID)
+	if err != nil {
+		log.Printf("Error getting review: %v", err)
+		b.respondError(i, "エラーが発生しました")
+		return
+	}
+	if review == nil {
+		b.respondError(i, "🎉 今日復習する問題はありません！")
+		return
 	}
+
+	question, err := b.db.GetQuestion(review.QuestionID)
+	if err != nil {
+		log.Printf("Error getting question: %v", err)
+		b.respondError(i, "エラーが発生しました")
+		return
+	}
+
+	// A review is a fresh attempt: earlier help no longer applies, so the
+	// answer is counted and reschedules the review
+	if err := b.db.ResetAssist(userID, question.ID); err != nil {
+		log.Printf("Error resetting assist: %v", err)
+	}
+
+	remaining, err := b.db.CountDueReviews(userID, i.GuildID)
+	if err != nil {
+		log.Printf("Error counting reviews: %v", err)
+	}
+
+	content := fmt.Sprintf("🔁 復習問題です（残り %d 問）", remaining)
+	if question.ExerciseType != db.ExerciseTranslation {
+		b.sendQuiz(i, private, question.ID, content, b.createExerciseEmbed(question), b.createExerciseComponents(question))
+		return
+	}
+	embed := b.createQuizEmbed(question.ID, question.Japanese, question.Theme, question.Difficulty, question.Direction)
+	b.sendQuiz(i, private, question.ID, content, embed, b.createQuizButtons())
 }
 
 // handleThemeCommand sets the user's quiz theme
-func (b *Bot) handleThemeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *Bot) handleThemeCommand(i *discordgo.InteractionCreate) {
 	options := i.ApplicationCommandData().Options
 	theme := options[0].StringValue()
-	userID := i.Member.User.ID
+	userID := interactionUserID(i)
 
-	user, err := b.db.GetOrCreateUser(userID)
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
 	if err != nil {
-		b.respondMessage(s, i, "エラーが発生しました")
+		b.respondMessage(i, "エラーが発生しました")
 		return
 	}
 
-	err = b.db.UpdateUserSettings(userID, user.Difficulty, theme)
+	err = b.db.UpdateUserSettings(userID, i.GuildID, user.Difficulty, theme)
 	if err != nil {
-		b.respondMessage(s, i, "設定の更新に失敗しました")
+		b.respondMessage(i, "設定の更新に失敗しました")
 		return
 	}
 
-	b.respondMessage(s, i, fmt.Sprintf("✅ テーマを「%s」に設定しました！", theme))
+	b.respondMessage(i, fmt.Sprintf("✅ テーマを「%s」に設定しました！", theme))
 }
 
-// handleStatsCommand shows user statistics
-func (b *Bot) handleStatsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	userID := i.Member.User.ID
+// handleStatsCommand shows user statistics for this server or across all servers
+func (b *Bot) handleStatsCommand(i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
 
-	stats, err := b.db.GetUserStats(userID)
+	scope := db.ScopeServer
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "scope" {
+			scope = db.StatsScope(opt.StringValue())
+		}
+	}
+
+	stats, err := b.db.GetUserStats(userID, i.GuildID, scope)
 	if err != nil {
-		b.respondMessage(s, i, "統計の取得に失敗しました")
+		b.respondMessage(i, "統計の取得に失敗しました")
 		return
 	}
 
-	embed := &discordgo.MessageEmbed{
-		Title: "📊 あなたの学習統計",
-		Color: 0x00D4AA,
-		Fields: []*discordgo.MessageEmbedField{
-			{Name: "総回答数", Value: fmt.Sprintf("%d 問", stats.TotalAnswers), Inline: true},
-			{Name: "平均スコア", Value: fmt.Sprintf("%.1f 点", stats.AverageScore), Inline: true},
-			{Name: "最高スコア", Value: fmt.Sprintf("%d 点", stats.HighestScore), Inline: true},
-			{Name: "今日の回答", Value: fmt.Sprintf("%d 問", stats.AnswersToday), Inline: true},
-		},
-	}
+	embed := b.createStatsEmbed(stats, scope)
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
 			Embeds: []*discordgo.MessageEmbed{embed},
@@ -128,20 +230,147 @@ func (b *Bot) handleStatsCommand(s *discordgo.Session, i *discordgo.InteractionC
 }
 
 // handleSettingsCommand shows current settings
-func (b *Bot) handleSettingsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	userID := i.Member.User.ID
+func (b *Bot) handleSettingsCommand(i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
 
-	user, err := b.db.GetOrCreateUser(userID)
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
 	if err != nil {
-		b.respondMessage(s, i, "設定の取得に失敗しました")
+		b.respondMessage(i, "設定の取得に失敗しました")
 		return
 	}
 
-	difficultyLabel := map[string]string{
-		"beginner":     "初級",
-		"intermediate": "中級",
-		"advanced":     "上級",
-	}[user.Difficulty]
+	embed := b.createSettingsEmbed(user)
+	components := b.createSettingsButtons()
+
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+// Helper functions
+
+// interactionUserID returns the ID of the user who triggered an interaction.
+// Member is only set in guilds; in DMs the user is on the interaction itself.
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// deliversPrivately reports whether a quiz requested in this interaction should
+// be sent by DM instead of in the channel
+func deliversPrivately(i *discordgo.InteractionCreate, user *db.User) bool {
+	return user.Delivery == db.DeliveryDM && i.GuildID != ""
+}
+
+// deferResponse acknowledges an interaction that will be answered later
+func (b *Bot) deferResponse(i *discordgo.InteractionCreate, ephemeral bool) {
+	resp := &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}
+	if ephemeral {
+		resp.Data = &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral}
+	}
+	b.session.InteractionRespond(i.Interaction, resp)
+}
+
+// sendQuiz completes a deferred interaction with a quiz, either in place or,
+// when private is set, by DM to the user. The sent message is recorded so
+// replies to it can be matched to the question.
+func (b *Bot) sendQuiz(i *discordgo.InteractionCreate, private bool, questionID int64, content string, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) {
+	userID := interactionUserID(i)
+
+	if !private {
+		edit := &discordgo.WebhookEdit{
+			Embeds:     &[]*discordgo.MessageEmbed{embed},
+			Components: &components,
+		}
+		if content != "" {
+			edit.Content = &content
+		}
+		msg, err := b.session.InteractionResponseEdit(i.Interaction, edit)
+		if err != nil {
+			log.Printf("Error sending quiz: %v", err)
+			return
+		}
+		b.recordQuizMessage(msg, questionID, userID)
+		return
+	}
+
+	msg, err := b.sendDirectMessage(userID, &discordgo.MessageSend{
+		Content:    content,
+		Embed:      embed,
+		Components: components,
+	})
+	if err != nil {
+		log.Printf("Error sending quiz by DM: %v", err)
+		b.respondError(i, "❌ DMを送信できませんでした。サーバーメンバーからのDMを許可してください")
+		return
+	}
+	b.recordQuizMessage(msg, questionID, userID)
+	b.respondError(i, "📬 DMに問題を送信しました！")
+}
+
+// recordQuizMessage remembers which question a sent message carries and who
+// may answer it
+func (b *Bot) recordQuizMessage(msg *discordgo.Message, questionID int64, recipientID string) {
+	if err := b.db.SaveQuizMessage(msg.ID, msg.ChannelID, questionID, recipientID); err != nil {
+		log.Printf("Error saving quiz message: %v", err)
+	}
+}
+
+// sendDirectMessage sends a message to a user's DM channel
+func (b *Bot) sendDirectMessage(userID string, data *discordgo.MessageSend) (*discordgo.Message, error) {
+	channel, err := b.session.UserChannelCreate(userID)
+	if err != nil {
+		return nil, err
+	}
+	return b.session.ChannelMessageSendComplex(channel.ID, data)
+}
+
+func (b *Bot) createStatsEmbed(stats *db.UserStats, scope db.StatsScope) *discordgo.MessageEmbed {
+	title := "📊 あなたの学習統計"
+	if scope == db.ScopeGlobal {
+		title += "（全サーバー）"
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: title,
+		Color: 0x00D4AA,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "総回答数", Value: fmt.Sprintf("%d 問", stats.TotalAnswers), Inline: true},
+			{Name: "平均スコア", Value: fmt.Sprintf("%.1f 点", stats.AverageScore), Inline: true},
+			{Name: "最高スコア", Value: fmt.Sprintf("%d 点", stats.HighestScore), Inline: true},
+			{Name: "今日の回答", Value: fmt.Sprintf("%d 問", stats.AnswersToday), Inline: true},
+			{Name: "🔥 連続記録", Value: fmt.Sprintf("%d 日", stats.CurrentStreak), Inline: true},
+			{Name: "🏆 最長記録", Value: fmt.Sprintf("%d 日", stats.LongestStreak), Inline: true},
+			{Name: "📈 改善した問題", Value: fmt.Sprintf("%d 問", stats.Improved), Inline: true},
+			{Name: "💡 ヒント使用", Value: fmt.Sprintf("%d 問", stats.HintsUsed), Inline: true},
+			{Name: "👀 答えを見た", Value: fmt.Sprintf("%d 問", stats.Revealed), Inline: true},
+		},
+	}
+}
+
+func (b *Bot) createSettingsEmbed(user *db.User) *discordgo.MessageEmbed {
+	difficultyLabel := userDifficultyName(user)
+
+	deliveryLabel := map[string]string{
+		db.DeliveryChannel: "チャンネル",
+		db.DeliveryDM:      "DM",
+	}[user.Delivery]
+
+	scheduleLabel := user.Schedule
+	if scheduleLabel == "" {
+		scheduleLabel = fmt.Sprintf("%d分ごと", b.config.Schedule.IntervalMinutes)
+	}
 
 	embed := &discordgo.MessageEmbed{
 		Title: "⚙️ 現在の設定",
@@ -150,24 +379,24 @@ func (b *Bot) handleSettingsCommand(s *discordgo.Session, i *discordgo.Interacti
 			{Name: "難易度", Value: difficultyLabel, Inline: true},
 			{Name: "テーマ", Value: user.Theme, Inline: true},
 			{Name: "定期出題", Value: map[bool]string{true: "ON", false: "OFF"}[user.ScheduleEnabled], Inline: true},
+			{Name: "配信時刻", Value: scheduleLabel, Inline: true},
+			{Name: "タイムゾーン", Value: user.Timezone, Inline: true},
+			{Name: "配信先", Value: deliveryLabel, Inline: true},
+			{Name: "出題方向", Value: directionLabels[user.Direction], Inline: true},
 		},
 	}
 
-	components := b.createSettingsButtons()
+	if user.Difficulty == db.DifficultyAuto {
+		if field := b.levelHistoryField(user); field != nil {
+			embed.Fields = append(embed.Fields, field)
+		}
+	}
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds:     []*discordgo.MessageEmbed{embed},
-			Components: components,
-		},
-	})
+	return embed
 }
 
-// Helper functions
-
-func (b *Bot) respondMessage(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+func (b *Bot) respondMessage(i *discordgo.InteractionCreate, message string) {
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
 			Content: message,
@@ -175,21 +404,17 @@ func (b *Bot) respondMessage(s *discordgo.Session, i *discordgo.InteractionCreat
 	})
 }
 
-func (b *Bot) respondError(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
-	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+func (b *Bot) respondError(i *discordgo.InteractionCreate, message string) {
+	b.session.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 		Content: &message,
 	})
 }
 
-func (b *Bot) createQuizEmbed(questionID int64, japanese, theme, difficulty string) *discordgo.MessageEmbed {
-	difficultyLabel := map[string]string{
-		"beginner":     "初級",
-		"intermediate": "中級",
-		"advanced":     "上級",
-	}[difficulty]
+func (b *Bot) createQuizEmbed(questionID int64, japanese, theme, difficulty, direction string) *discordgo.MessageEmbed {
+	difficultyLabel := difficultyName(difficulty)
 
 	return &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("📝 英作文問題 #%d", questionID),
+		Title:       fmt.Sprintf("📝 %s #%d", quizKind(direction), questionID),
 		Description: fmt.Sprintf("「%s」", japanese),
 		Color:       0x5865F2,
 		Fields: []*discordgo.MessageEmbedField{
@@ -197,13 +422,20 @@ func (b *Bot) createQuizEmbed(questionID int64, japanese, theme, difficulty stri
 			{Name: "📊 難易度", Value: difficultyLabel, Inline: true},
 		},
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "💡 このメッセージに返信して回答してください！",
+			Text: "💡 「回答する」ボタンか、このメッセージへの返信で回答してください！",
 		},
 	}
 }
 
 func (b *Bot) createQuizButtons() []discordgo.MessageComponent {
 	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				createAnswerButton(),
+				createHintButton(),
+				createRevealButton(),
+			},
+		},
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
@@ -237,6 +469,20 @@ func (b *Bot) createSettingsButtons() []discordgo.MessageComponent {
 						{Label: "初級", Value: "beginner", Description: "シンプルな文法、基本語彙"},
 						{Label: "中級", Value: "intermediate", Description: "複文、一般的な表現"},
 						{Label: "上級", Value: "advanced", Description: "複雑な文法、慣用句"},
+						{Label: "自動 (CEFR)", Value: db.DifficultyAuto, Description: "成績に合わせて A1〜C2 で自動調整"},
+					},
+				},
+			},
+		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    "direction_select",
+					Placeholder: "出題方向を選択",
+					Options: []discordgo.SelectMenuOption{
+						{Label: directionLabels[db.DirectionJaEn], Value: db.DirectionJaEn, Description: "日本語の文を英訳する"},
+						{Label: directionLabels[db.DirectionEnJa], Value: db.DirectionEnJa, Description: "英語の文を和訳する"},
+						{Label: directionLabels[db.DirectionMixed], Value: db.DirectionMixed, Description: "両方向からランダムに出題"},
 					},
 				},
 			},
@@ -253,6 +499,16 @@ func (b *Bot) createSettingsButtons() []discordgo.MessageComponent {
 					Style:    discordgo.SecondaryButton,
 					CustomID: "schedule_toggle",
 				},
+				discordgo.Button{
+					Label:    "🕒 配信時刻",
+					Style:    discordgo.SecondaryButton,
+					CustomID: "schedule_modal",
+				},
+				discordgo.Button{
+					Label:    "📬 配信先切替",
+					Style:    discordgo.SecondaryButton,
+					CustomID: "delivery_toggle",
+				},
 			},
 		},
 	}
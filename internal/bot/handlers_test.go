@@ -0,0 +1,558 @@
+package bot
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/config"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+const (
+	testBotUserID = "bot"
+	testUserID    = "user-1"
+	testChannelID = "channel-1"
+	testGuildID   = "guild-1"
+)
+
+// newTestBot creates a Bot backed by a temporary database, the fake model and a recording session
+func newTestBot(t *testing.T) (*Bot, *recordingSession) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	database, err := db.New(path)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	cfg := &config.Config{
+		Discord:  config.DiscordConfig{ChannelID: testChannelID},
+		Schedule: config.ScheduleConfig{IntervalMinutes: 60},
+		Database: config.DatabaseConfig{Path: path},
+	}
+
+	session := &recordingSession{}
+	b := newBot(session, cfg, database, claude.NewFake())
+	b.userID = testBotUserID
+	return b, session
+}
+
+func testMember(userID string) *discordgo.Member {
+	return &discordgo.Member{User: &discordgo.User{ID: userID}}
+}
+
+// slashCommand builds a slash command interaction from a guild member
+func slashCommand(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:      discordgo.InteractionApplicationCommand,
+		GuildID:   testGuildID,
+		ChannelID: testChannelID,
+		Member:    testMember(testUserID),
+		Data:      discordgo.ApplicationCommandInteractionData{Name: name, Options: options},
+	}}
+}
+
+// componentClick builds a button or select menu interaction from a guild member
+func componentClick(customID string, values ...string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:      discordgo.InteractionMessageComponent,
+		GuildID:   testGuildID,
+		ChannelID: testChannelID,
+		Member:    testMember(testUserID),
+		Data:      discordgo.MessageComponentInteractionData{CustomID: customID, Values: values},
+	}}
+}
+
+// modalSubmit builds a modal submission with the given text input values
+func modalSubmit(customID string, inputs map[string]string) *discordgo.InteractionCreate {
+	var rows []discordgo.MessageComponent
+	for id, value := range inputs {
+		rows = append(rows, &discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			&discordgo.TextInput{CustomID: id, Value: value},
+		}})
+	}
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:      discordgo.InteractionModalSubmit,
+		GuildID:   testGuildID,
+		ChannelID: testChannelID,
+		Member:    testMember(testUserID),
+		Data:      discordgo.ModalSubmitInteractionData{CustomID: customID, Components: rows},
+	}}
+}
+
+// inDM moves an interaction into a DM, where Discord sets User instead of Member
+func inDM(i *discordgo.InteractionCreate) *discordgo.InteractionCreate {
+	i.User = i.Member.User
+	i.Member = nil
+	i.GuildID = ""
+	i.ChannelID = "dm-" + i.User.ID
+	return i
+}
+
+// replyTo builds a message from the test user replying to a bot message. The
+// reply is in the test guild unless the message is in a DM channel.
+func replyTo(message *discordgo.Message, content string) *discordgo.MessageCreate {
+	guildID := testGuildID
+	if strings.HasPrefix(message.ChannelID, "dm-") {
+		guildID = ""
+	}
+	return &discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:                "reply-1",
+		ChannelID:         message.ChannelID,
+		GuildID:           guildID,
+		Content:           content,
+		Author:            &discordgo.User{ID: testUserID},
+		ReferencedMessage: message,
+	}}
+}
+
+func TestOnInteractionCreate_Responses(t *testing.T) {
+	tests := []struct {
+		name        string
+		interaction *discordgo.InteractionCreate
+		wantType    discordgo.InteractionResponseType
+		wantContent string
+		wantEmbed   string
+		ephemeral   bool
+	}{
+		{
+			name:        "theme command",
+			interaction: slashCommand("theme", &discordgo.ApplicationCommandInteractionDataOption{Name: "theme", Type: discordgo.ApplicationCommandOptionString, Value: "旅行"}),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantContent: "テーマを「旅行」に設定しました",
+		},
+		{
+			name:        "stats command",
+			interaction: slashCommand("stats"),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantEmbed:   "📊 あなたの学習統計",
+		},
+		{
+			name:        "settings command",
+			interaction: slashCommand("settings"),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantEmbed:   "⚙️ 現在の設定",
+		},
+		{
+			name:        "schedule command opens modal",
+			interaction: slashCommand("schedule"),
+			wantType:    discordgo.InteractionResponseModal,
+		},
+		{
+			name:        "stats command in DM",
+			interaction: inDM(slashCommand("stats")),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantEmbed:   "📊 あなたの学習統計",
+		},
+		{
+			name:        "settings button in DM",
+			interaction: inDM(componentClick("settings_open")),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantEmbed:   "⚙️ 現在の設定",
+			ephemeral:   true,
+		},
+		{
+			name:        "delivery toggle",
+			interaction: componentClick("delivery_toggle"),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantContent: "配信先をDMにしました",
+			ephemeral:   true,
+		},
+		{
+			name:        "stats button",
+			interaction: componentClick("stats_show"),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantEmbed:   "📊 あなたの学習統計",
+			ephemeral:   true,
+		},
+		{
+			name:        "difficulty select",
+			interaction: componentClick("difficulty_select", "advanced"),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantContent: "難易度を「上級」に設定しました",
+			ephemeral:   true,
+		},
+		{
+			name:        "direction select",
+			interaction: componentClick("direction_select", "en-ja"),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantContent: "出題方向を「英語→日本語」に設定しました",
+			ephemeral:   true,
+		},
+		{
+			name:        "schedule toggle",
+			interaction: componentClick("schedule_toggle"),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantContent: "定期出題を ON にしました",
+			ephemeral:   true,
+		},
+		{
+			name:        "theme modal submit",
+			interaction: modalSubmit("theme_modal_submit", map[string]string{"theme_input": "料理"}),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantContent: "テーマを「料理」に設定しました",
+			ephemeral:   true,
+		},
+		{
+			name:        "schedule modal rejects unknown time zone",
+			interaction: modalSubmit("schedule_modal_submit", map[string]string{"schedule_input": "07:30", "timezone_input": "Mars/Base"}),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantContent: "タイムゾーン「Mars/Base」が見つかりません",
+			ephemeral:   true,
+		},
+		{
+			name:        "schedule modal rejects bad schedule",
+			interaction: modalSubmit("schedule_modal_submit", map[string]string{"schedule_input": "sometimes", "timezone_input": "UTC"}),
+			wantType:    discordgo.InteractionResponseChannelMessageWithSource,
+			wantContent: "配信時刻の形式が正しくありません",
+			ephemeral:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, session := newTestBot(t)
+
+			b.onInteractionCreate(nil, tt.interaction)
+
+			resp := session.lastResponse()
+			if resp == nil {
+				t.Fatal("Expected an interaction response")
+			}
+			if resp.Type != tt.wantType {
+				t.Errorf("Expected response type %v, got %v", tt.wantType, resp.Type)
+			}
+			if tt.wantContent != "" && (resp.Data == nil || !strings.Contains(resp.Data.Content, tt.wantContent)) {
+				t.Errorf("Expected content containing %q, got %+v", tt.wantContent, resp.Data)
+			}
+			if tt.wantEmbed != "" && (resp.Data == nil || len(resp.Data.Embeds) == 0 || resp.Data.Embeds[0].Title != tt.wantEmbed) {
+				t.Errorf("Expected embed %q, got %+v", tt.wantEmbed, resp.Data)
+			}
+			if tt.ephemeral && resp.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+				t.Error("Expected ephemeral response")
+			}
+		})
+	}
+}
+
+func TestOnInteractionCreate_UpdatesSettings(t *testing.T) {
+	b, _ := newTestBot(t)
+
+	b.onInteractionCreate(nil, componentClick("difficulty_select", "beginner"))
+	b.onInteractionCreate(nil, modalSubmit("schedule_modal_submit", map[string]string{
+		"schedule_input": "weekdays 07:30",
+		"timezone_input": "UTC",
+	}))
+
+	user, err := b.db.GetOrCreateUser(testUserID, testGuildID)
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if user.Difficulty != "beginner" {
+		t.Errorf("Expected difficulty 'beginner', got '%s'", user.Difficulty)
+	}
+	if user.Schedule != "weekdays 07:30" || user.Timezone != "UTC" {
+		t.Errorf("Expected schedule saved, got %q %q", user.Schedule, user.Timezone)
+	}
+}
+
+func TestQuizDirection_EnglishToJapanese(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, componentClick("direction_select", db.DirectionEnJa))
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+
+	quiz := session.lastMessage()
+	if !strings.Contains(quiz.Embeds[0].Title, "和訳問題") || quiz.Embeds[0].Description != "「I drink coffee every morning.」" {
+		t.Fatalf("Expected an English sentence to translate, got %+v", quiz.Embeds[0])
+	}
+
+	b.onInteractionCreate(nil, clickOn(quiz, "answer_open"))
+	modal := session.lastResponse()
+	input := modal.Data.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.TextInput)
+	if input.Label != "和訳" {
+		t.Errorf("Expected a Japanese answer input, got %q", input.Label)
+	}
+
+	b.onInteractionCreate(nil, modalSubmit(modal.Data.CustomID, map[string]string{
+		"answer_input": "私は毎朝コーヒーを飲みます。",
+	}))
+
+	stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+	if stats.HighestScore != 100 {
+		t.Errorf("Expected the Japanese answer graded against the Japanese model answer, got %+v", stats)
+	}
+}
+
+func TestQuizReplyEvaluation(t *testing.T) {
+	tests := []struct {
+		name      string
+		answer    string
+		wantScore string
+	}{
+		{"perfect answer", "I drink coffee every morning.", "**100** / 100"},
+		{"partial answer", "I drink coffee", "**60** / 100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, session := newTestBot(t)
+
+			// Ask for a quiz
+			b.onInteractionCreate(nil, slashCommand("quiz"))
+			if resp := session.lastResponse(); resp == nil || resp.Type != discordgo.InteractionResponseDeferredChannelMessageWithSource {
+				t.Fatalf("Expected deferred response, got %+v", resp)
+			}
+			quiz := session.lastMessage()
+			if quiz == nil || len(quiz.Embeds) == 0 {
+				t.Fatalf("Expected quiz embed, got %+v", quiz)
+			}
+
+			// Reply with an answer
+			b.onMessageCreate(nil, replyTo(quiz, tt.answer))
+
+			if len(session.sent) == 0 {
+				t.Fatal("Expected an evaluation message")
+			}
+			evaluation := session.sent[0]
+			if len(evaluation.Embeds) == 0 {
+				t.Fatalf("Expected evaluation embed, got %+v", evaluation)
+			}
+			var score string
+			for _, f := range evaluation.Embeds[0].Fields {
+				if f.Name == "📊 スコア" {
+					score = f.Value
+				}
+			}
+			if score != tt.wantScore {
+				t.Errorf("Expected score %q, got %q", tt.wantScore, score)
+			}
+
+			stats, err := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+			if err != nil {
+				t.Fatalf("Failed to get stats: %v", err)
+			}
+			if stats.TotalAnswers != 1 {
+				t.Errorf("Expected 1 saved answer, got %d", stats.TotalAnswers)
+			}
+		})
+	}
+}
+
+func TestQuizDelivery(t *testing.T) {
+	tests := []struct {
+		name        string
+		delivery    string
+		interaction *discordgo.InteractionCreate
+		wantDM      bool
+	}{
+		{"channel delivery in guild", "channel", slashCommand("quiz"), false},
+		{"DM delivery in guild", "dm", slashCommand("quiz"), true},
+		{"next button with DM delivery", "dm", componentClick("quiz_next"), true},
+		{"DM delivery inside DM answers in place", "dm", inDM(slashCommand("quiz")), false},
+		{"channel delivery inside DM", "channel", inDM(slashCommand("quiz")), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, session := newTestBot(t)
+			b.db.GetOrCreateUser(testUserID, tt.interaction.GuildID)
+			b.db.UpdateUserDelivery(testUserID, tt.interaction.GuildID, tt.delivery)
+
+			b.onInteractionCreate(nil, tt.interaction)
+
+			resp := session.lastResponse()
+			ephemeral := resp.Data != nil && resp.Data.Flags&discordgo.MessageFlagsEphemeral != 0
+			if ephemeral != tt.wantDM {
+				t.Errorf("Expected ephemeral deferral %v, got %v", tt.wantDM, ephemeral)
+			}
+
+			if tt.wantDM {
+				if len(session.sent) != 1 || session.sent[0].ChannelID != "dm-"+testUserID {
+					t.Fatalf("Expected quiz sent by DM, got %+v", session.sent)
+				}
+				if edit := session.lastEdit(); edit == nil || edit.Content == nil || !strings.Contains(*edit.Content, "DMに問題を送信しました") {
+					t.Errorf("Expected DM confirmation, got %+v", edit)
+				}
+				return
+			}
+
+			if len(session.sent) != 0 {
+				t.Errorf("Expected no channel messages, got %+v", session.sent)
+			}
+			if edit := session.lastEdit(); edit == nil || edit.Embeds == nil {
+				t.Errorf("Expected quiz in the interaction response, got %+v", edit)
+			}
+		})
+	}
+}
+
+func TestQuizReplyInDM(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, inDM(slashCommand("quiz")))
+	quiz := session.lastMessage()
+	if quiz == nil || len(quiz.Embeds) == 0 {
+		t.Fatalf("Expected quiz embed, got %+v", quiz)
+	}
+
+	b.onMessageCreate(nil, replyTo(quiz, "I drink coffee every morning."))
+
+	if len(session.sent) == 0 || session.sent[0].ChannelID != "dm-"+testUserID {
+		t.Fatalf("Expected evaluation in the DM channel, got %+v", session.sent)
+	}
+}
+
+func TestQuizReply_OtherUser(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	quiz := session.lastMessage()
+
+	reply := replyTo(quiz, "I drink coffee every morning.")
+	reply.Author.ID = "someone-else"
+	b.onMessageCreate(nil, reply)
+
+	// The warning goes to the replier's DMs, not the channel
+	if len(session.sent) != 1 || session.sent[0].ChannelID != "dm-someone-else" || !strings.Contains(session.sent[0].Content, "他のユーザー宛て") {
+		t.Fatalf("Expected a warning by DM, got %+v", session.sent)
+	}
+	if stats, _ := b.db.GetUserStats("someone-else", testGuildID, db.ScopeGlobal); stats.TotalAnswers != 0 {
+		t.Errorf("Expected no answer saved, got %d", stats.TotalAnswers)
+	}
+}
+
+func TestQuizReply_LegacyQuiz(t *testing.T) {
+	b, session := newTestBot(t)
+
+	// Quizzes posted before quiz messages were recorded carry the ID in the title
+	questionID, _ := b.db.SaveQuestion(testGuildID, "私は毎朝コーヒーを飲みます。", "beginner", "日常会話", db.DirectionJaEn)
+	legacy := &discordgo.Message{
+		ID:        "legacy-1",
+		ChannelID: testChannelID,
+		Author:    &discordgo.User{ID: testBotUserID},
+		Embeds:    []*discordgo.MessageEmbed{{Title: fmt.Sprintf("📝 英作文問題 #%d", questionID)}},
+	}
+	b.onMessageCreate(nil, replyTo(legacy, "I drink coffee every morning."))
+
+	if len(session.sent) != 1 || len(session.sent[0].Embeds) == 0 {
+		t.Fatalf("Expected the legacy quiz answer evaluated, got %+v", session.sent)
+	}
+	if stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer); stats.TotalAnswers != 1 {
+		t.Errorf("Expected the answer saved, got %+v", stats)
+	}
+}
+
+func TestQuizReply_DMQuizRecorded(t *testing.T) {
+	b, session := newTestBot(t)
+	b.db.GetOrCreateUser(testUserID, testGuildID)
+	b.db.UpdateUserDelivery(testUserID, testGuildID, "dm")
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	if len(session.sent) != 1 {
+		t.Fatalf("Expected quiz sent by DM, got %+v", session.sent)
+	}
+	quiz := session.messages[0]
+
+	b.onMessageCreate(nil, replyTo(quiz, "I drink coffee every morning."))
+
+	if len(session.sent) != 2 || len(session.sent[1].Embeds) == 0 {
+		t.Fatalf("Expected an evaluation for the DM quiz, got %+v", session.sent)
+	}
+
+	question, _ := b.db.GetQuizMessage(quiz.ID)
+	answer, err := b.db.GetLastAnswer(testUserID, question.QuestionID)
+	if err != nil || answer == nil {
+		t.Fatalf("Expected the answer saved, got %v", err)
+	}
+	if answer.GuildID != testGuildID {
+		t.Errorf("Expected the DM answer recorded in the quiz's guild, got %q", answer.GuildID)
+	}
+
+	stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+	if stats.TotalAnswers != 1 {
+		t.Errorf("Expected the DM answer in the server stats, got %+v", stats)
+	}
+}
+
+func TestOnMessageCreate_Ignored(t *testing.T) {
+	quiz := &discordgo.Message{
+		ID:        "quiz-1",
+		ChannelID: testChannelID,
+		Author:    &discordgo.User{ID: testBotUserID},
+		Embeds:    []*discordgo.MessageEmbed{{Title: "📝 英作文問題 #1"}},
+	}
+
+	tests := []struct {
+		name    string
+		message *discordgo.MessageCreate
+	}{
+		{
+			name:    "not a reply",
+			message: &discordgo.MessageCreate{Message: &discordgo.Message{Author: &discordgo.User{ID: testUserID}, Content: "hello"}},
+		},
+		{
+			name: "from a bot",
+			message: &discordgo.MessageCreate{Message: &discordgo.Message{
+				Author: &discordgo.User{ID: "other-bot", Bot: true}, Content: "hello", ReferencedMessage: quiz,
+			}},
+		},
+		{
+			name: "reply to another user's message",
+			message: replyTo(&discordgo.Message{
+				Author: &discordgo.User{ID: "someone"},
+				Embeds: quiz.Embeds,
+			}, "hello"),
+		},
+		{
+			name:    "reply to a message without a question",
+			message: replyTo(&discordgo.Message{Author: &discordgo.User{ID: testBotUserID}, Content: "hi"}, "hello"),
+		},
+		{
+			name:    "reply to an unrecorded embed with an unknown question",
+			message: replyTo(quiz, "hello"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, session := newTestBot(t)
+
+			b.onMessageCreate(nil, tt.message)
+
+			if len(session.sent) != 0 {
+				t.Errorf("Expected no messages, got %+v", session.sent)
+			}
+		})
+	}
+}
+
+func TestStatsCommand_Scope(t *testing.T) {
+	b, session := newTestBot(t)
+	qID, _ := b.db.SaveQuestion("other-guild", "テスト", "beginner", "テスト", db.DirectionJaEn)
+	b.db.SaveAnswer(testUserID, "other-guild", qID, "test", "test", 80, "")
+
+	tests := []struct {
+		scope     string
+		wantTitle string
+		wantTotal string
+	}{
+		{"server", "📊 あなたの学習統計", "0 問"},
+		{"global", "📊 あなたの学習統計（全サーバー）", "1 問"},
+	}
+	for _, tt := range tests {
+		b.onInteractionCreate(nil, slashCommand("stats", &discordgo.ApplicationCommandInteractionDataOption{
+			Name: "scope", Type: discordgo.ApplicationCommandOptionString, Value: tt.scope,
+		}))
+
+		embed := session.lastResponse().Data.Embeds[0]
+		if embed.Title != tt.wantTitle || embed.Fields[0].Value != tt.wantTotal {
+			t.Errorf("scope %s: expected %q with %s, got %q with %s", tt.scope, tt.wantTitle, tt.wantTotal, embed.Title, embed.Fields[0].Value)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// leaderboardPageSize is the number of users shown per leaderboard page
+const leaderboardPageSize = 10
+
+// leaderboardPrefix starts the custom ID of leaderboard paging buttons, which
+// carry the rest of the view as "leaderboard:<period>:<metric>:<page>"
+const leaderboardPrefix = "leaderboard:"
+
+// leaderboardCommand is the /leaderboard command
+var leaderboardCommand = &discordgo.ApplicationCommand{
+	Name:        "leaderboard",
+	Description: "Show this server's leaderboard",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "period",
+			Description: "Which answers to count (default: week)",
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "過去7日間 (week)", Value: string(db.PeriodWeek)},
+				{Name: "過去30日間 (month)", Value: string(db.PeriodMonth)},
+				{Name: "全期間 (all)", Value: string(db.PeriodAll)},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "metric",
+			Description: "What to rank by (default: average score)",
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "平均スコア (average)", Value: string(db.MetricAverage)},
+				{Name: "回答数 (answers)", Value: string(db.MetricAnswers)},
+				{Name: "連続記録 (streak)", Value: string(db.MetricStreak)},
+			},
+		},
+	},
+}
+
+// leaderboardView identifies one page of a leaderboard
+type leaderboardView struct {
+	period db.LeaderboardPeriod
+	metric db.LeaderboardMetric
+	page   int
+}
+
+// customID returns the custom ID of a button that shows the view
+func (v leaderboardView) customID() string {
+	return fmt.Sprintf("%s%s:%s:%d", leaderboardPrefix, v.period, v.metric, v.page)
+}
+
+// parseLeaderboardView parses a paging button's custom ID
+func parseLeaderboardView(customID string) (leaderboardView, bool) {
+	parts := strings.Split(strings.TrimPrefix(customID, leaderboardPrefix), ":")
+	if len(parts) != 3 {
+		return leaderboardView{}, false
+	}
+	page, err := strconv.Atoi(parts[2])
+	if err != nil || page < 0 {
+		return leaderboardView{}, false
+	}
+	return leaderboardView{period: db.LeaderboardPeriod(parts[0]), metric: db.LeaderboardMetric(parts[1]), page: page}, true
+}
+
+// handleLeaderboardCommand shows the first page of the requested leaderboard
+func (b *Bot) handleLeaderboardCommand(i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		b.respondComponentMessage(i, "❌ /leaderboard はサーバー内でのみ使用できます")
+		return
+	}
+
+	view := leaderboardView{period: db.PeriodWeek, metric: db.MetricAverage}
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "period":
+			view.period = db.LeaderboardPeriod(opt.StringValue())
+		case "metric":
+			view.metric = db.LeaderboardMetric(opt.StringValue())
+		}
+	}
+
+	b.respondLeaderboard(i, view, discordgo.InteractionResponseChannelMessageWithSource)
+}
+
+// handleLeaderboardButton replaces the leaderboard message with another page
+func (b *Bot) handleLeaderboardButton(i *discordgo.InteractionCreate, customID string) {
+	view, ok := parseLeaderboardView(customID)
+	if !ok || i.GuildID == "" {
+		b.respondComponentMessage(i, "エラーが発生しました")
+		return
+	}
+
+	b.respondLeaderboard(i, view, discordgo.InteractionResponseUpdateMessage)
+}
+
+// respondLeaderboard renders a leaderboard page as a new message or an update
+func (b *Bot) respondLeaderboard(i *discordgo.InteractionCreate, view leaderboardView, responseType discordgo.InteractionResponseType) {
+	entries, total, err := b.db.GetLeaderboard(i.GuildID, view.period, view.metric, leaderboardPageSize, view.page*leaderboardPageSize)
+	if err != nil {
+		b.respondComponentMessage(i, "リーダーボードの取得に失敗しました")
+		return
+	}
+
+	pages := (total + leaderboardPageSize - 1) / leaderboardPageSize
+	if pages == 0 {
+		pages = 1
+	}
+
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: responseType,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{b.createLeaderboardEmbed(view, entries, pages)},
+			Components: b.createLeaderboardButtons(view, pages),
+			AllowedMentions: &discordgo.MessageAllowedMentions{
+				Parse: []discordgo.AllowedMentionType{},
+			},
+		},
+	})
+}
+
+// createLeaderboardEmbed creates the embed for one leaderboard page
+func (b *Bot) createLeaderboardEmbed(view leaderboardView, entries []db.LeaderboardEntry, pages int) *discordgo.MessageEmbed {
+	periodLabel := map[db.LeaderboardPeriod]string{
+		db.PeriodWeek:  "過去7日間",
+		db.PeriodMonth: "過去30日間",
+		db.PeriodAll:   "全期間",
+	}[view.period]
+
+	metricLabel := map[db.LeaderboardMetric]string{
+		db.MetricAverage: "平均スコア",
+		db.MetricAnswers: "回答数",
+		db.MetricStreak:  "連続記録",
+	}[view.metric]
+
+	var lines []string
+	for _, e := range entries {
+		rank := fmt.Sprintf("**%d.**", e.Rank)
+		switch e.Rank {
+		case 1:
+			rank = "🥇"
+		case 2:
+			rank = "🥈"
+		case 3:
+			rank = "🥉"
+		}
+
+		var value string
+		switch view.metric {
+		case db.MetricAverage:
+			value = fmt.Sprintf("%.1f 点（%d 問）", e.Value, e.Answers)
+		case db.MetricAnswers:
+			value = fmt.Sprintf("%d 問", int(e.Value))
+		case db.MetricStreak:
+			value = fmt.Sprintf("%d 日", int(e.Value))
+		}
+
+		lines = append(lines, fmt.Sprintf("%s <@%s> — %s", rank, e.DiscordID, value))
+	}
+
+	description := strings.Join(lines, "\n")
+	if description == "" {
+		description = "まだ回答がありません。/quiz で挑戦しましょう！"
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("🏆 リーダーボード — %s（%s）", metricLabel, periodLabel),
+		Description: description,
+		Color:       0xFFD700,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("ページ %d / %d", view.page+1, pages),
+		},
+	}
+}
+
+// createLeaderboardButtons creates the prev/next buttons for a leaderboard page
+func (b *Bot) createLeaderboardButtons(view leaderboardView, pages int) []discordgo.MessageComponent {
+	prev, next := view, view
+	prev.page--
+	next.page++
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "◀️ 前へ",
+					Style:    discordgo.SecondaryButton,
+					CustomID: prev.customID(),
+					Disabled: view.page == 0,
+				},
+				discordgo.Button{
+					Label:    "次へ ▶️",
+					Style:    discordgo.SecondaryButton,
+					CustomID: next.customID(),
+					Disabled: view.page+1 >= pages,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+func TestLeaderboard_Paging(t *testing.T) {
+	b, session := newTestBot(t)
+
+	qID, _ := b.db.SaveQuestion(testGuildID, "テスト", "beginner", "テスト", db.DirectionJaEn)
+	for n := 0; n < leaderboardPageSize+2; n++ {
+		b.db.SaveAnswer(fmt.Sprintf("user-%02d", n), testGuildID, qID, "test", "test", 50+n, "")
+	}
+
+	b.onInteractionCreate(nil, slashCommand("leaderboard", &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "metric", Type: discordgo.ApplicationCommandOptionString, Value: "average",
+	}))
+
+	resp := session.lastResponse()
+	if resp.Type != discordgo.InteractionResponseChannelMessageWithSource {
+		t.Fatalf("Expected a new message, got %+v", resp)
+	}
+	embed := resp.Data.Embeds[0]
+	if embed.Footer.Text != "ページ 1 / 2" || !strings.HasPrefix(embed.Description, "🥇 <@user-11>") {
+		t.Errorf("Unexpected first page: %q %q", embed.Footer.Text, embed.Description)
+	}
+
+	buttons := resp.Data.Components[0].(discordgo.ActionsRow).Components
+	prev, next := buttons[0].(discordgo.Button), buttons[1].(discordgo.Button)
+	if !prev.Disabled || next.Disabled {
+		t.Errorf("Expected only next enabled on the first page")
+	}
+
+	b.onInteractionCreate(nil, componentClick(next.CustomID))
+
+	resp = session.lastResponse()
+	if resp.Type != discordgo.InteractionResponseUpdateMessage {
+		t.Fatalf("Expected the message to be updated, got %+v", resp)
+	}
+	embed = resp.Data.Embeds[0]
+	if embed.Footer.Text != "ページ 2 / 2" || !strings.HasPrefix(embed.Description, "**11.** <@user-01>") {
+		t.Errorf("Unexpected second page: %q %q", embed.Footer.Text, embed.Description)
+	}
+}
+
+func TestLeaderboard_RequiresGuild(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, inDM(slashCommand("leaderboard")))
+
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "サーバー内でのみ") {
+		t.Errorf("Expected guild-only error, got %+v", resp.Data)
+	}
+}
@@ -1,17 +1,22 @@
 package bot
 
 import (
-	"context"
 	"fmt"
 	"log"
-	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
 )
 
+// legacyQuizPrefix starts the title of quizzes posted before quiz messages
+// were recorded, followed by the question ID
+const legacyQuizPrefix = "📝 英作文問題 #"
+
 // onMessageCreate handles incoming messages (for reply-based answers)
-func (b *Bot) onMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
+func (b *Bot) onMessageCreate(_ *discordgo.Session, m *discordgo.MessageCreate) {
 	// Ignore bot messages
 	if m.Author.Bot {
 		return
@@ -23,77 +28,232 @@ func (b *Bot) onMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate)
 	}
 
 	// Check if the referenced message is from our bot
-	if m.ReferencedMessage.Author.ID != s.State.User.ID {
+	if m.ReferencedMessage.Author == nil || m.ReferencedMessage.Author.ID != b.userID {
 		return
 	}
 
-	// Check if it's a quiz message by looking for the embed
-	if len(m.ReferencedMessage.Embeds) == 0 {
+	// Look up which question the referenced message carries
+	quiz, err := b.db.GetQuizMessage(m.ReferencedMessage.ID)
+	if err != nil {
+		log.Printf("Error getting quiz message: %v", err)
 		return
 	}
-
-	embed := m.ReferencedMessage.Embeds[0]
-	if embed.Title == "" || len(embed.Title) < 10 {
+	if quiz == nil {
+		quiz = b.legacyQuizMessage(m.ReferencedMessage)
+	}
+	if quiz == nil {
 		return
 	}
 
-	// Extract question ID from title (e.g., "📝 英作文問題 #42")
-	questionID := extractQuestionID(embed.Title)
-	if questionID == 0 {
+	// Challenge answers are collected and evaluated together when it closes
+	challenge, err := b.db.GetChallenge(quiz.MessageID)
+	if err != nil {
+		log.Printf("Error getting challenge: %v", err)
+		return
+	}
+	if challenge != nil {
+		b.handleChallengeReply(m, challenge)
 		return
 	}
 
-	// Get the question from database
-	question, err := b.db.GetQuestion(questionID)
-	if err != nil {
-		log.Printf("Error getting question: %v", err)
+	// Only the intended recipient may answer a personal quiz. Others are told
+	// by DM rather than in the channel, falling back to a reaction.
+	if quiz.DiscordID != "" && quiz.DiscordID != m.Author.ID {
+		_, err := b.sendDirectMessage(m.Author.ID, &discordgo.MessageSend{
+			Content: "⚠️ 返信した問題は他のユーザー宛てです。/quiz で自分の問題を出題できます",
+		})
+		if err != nil {
+			log.Printf("Error sending warning by DM: %v", err)
+			b.session.MessageReactionAdd(m.ChannelID, m.ID, "🚫")
+		}
 		return
 	}
 
 	// Show typing indicator
-	s.ChannelTyping(m.ChannelID)
+	b.session.ChannelTyping(m.ChannelID)
 
-	// Evaluate the answer using Claude
-	ctx := context.Background()
-	result, err := b.claude.EvaluateAnswer(ctx, question.Japanese, m.Content)
+	eval, err := b.evaluateAnswer(quiz, m.Author.ID, m.Content)
 	if err != nil {
 		log.Printf("Error evaluating answer: %v", err)
-		s.ChannelMessageSend(m.ChannelID, "❌ 回答の評価に失敗しました")
+		b.session.ChannelMessageSend(m.ChannelID, "❌ 回答の評価に失敗しました")
 		return
 	}
 
-	// Save the answer to database
-	err = b.db.SaveAnswer(m.Author.ID, questionID, m.Content, result.ModelAnswer, result.Score, result.Feedback)
-	if err != nil {
-		log.Printf("Error saving answer: %v", err)
-	}
-
 	// Create response embed
-	responseEmbed := b.createEvaluationEmbed(m.Content, result.Score, result.Feedback, result.ModelAnswer)
+	responseEmbed := b.createEvaluationEmbed(m.Content, eval)
 
 	// Send response
-	_, err = s.ChannelMessageSendEmbed(m.ChannelID, responseEmbed)
+	_, err = b.session.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Embed:      responseEmbed,
+		Components: createEvaluationButtons(quiz.MessageID),
+	})
 	if err != nil {
 		log.Printf("Error sending evaluation: %v", err)
 	}
+
+	if eval.recorded {
+		b.announceStreakMilestone(m.ChannelID, m.GuildID, m.Author.ID)
+	}
+}
+
+// legacyQuizMessage maps a quiz posted before quiz messages were recorded to
+// its question by the ID in its title, returning nil for any other message or
+// an unknown question. Anyone may answer these quizzes, as before.
+func (b *Bot) legacyQuizMessage(msg *discordgo.Message) *db.QuizMessage {
+	if len(msg.Embeds) == 0 {
+		return nil
+	}
+	id, ok := strings.CutPrefix(msg.Embeds[0].Title, legacyQuizPrefix)
+	if !ok {
+		return nil
+	}
+	questionID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil
+	}
+	if _, err := b.db.GetQuestion(questionID); err != nil {
+		return nil
+	}
+	return &db.QuizMessage{MessageID: msg.ID, ChannelID: msg.ChannelID, QuestionID: questionID}
+}
+
+// evaluation is a graded answer together with the help the user took on the
+// question, their previous attempt at it and the level change it caused, if
+// any. recorded reports whether the answer counts towards stats.
+type evaluation struct {
+	result   *claude.EvaluationResult
+	assist   *db.Assist
+	previous *db.Answer
+	level    *db.LevelChange
+	recorded bool
+}
+
+// evaluateAnswer grades a user's answer to a quiz, records it in their stats
+// and review queue and adjusts their level in automatic difficulty. Answers
+// given after a hint lose hintPenalty points; answers given after the model
+// answer was revealed or shown with an earlier evaluation are graded as
+// practice and leave the review queue alone until /review serves it again.
+// The answer is recorded in the guild the question was asked in, even when it
+// arrives by DM. Vocabulary quiz answers only reschedule their notebook
+// entry. Replies and the answer modal share it.
+func (b *Bot) evaluateAnswer(quiz *db.QuizMessage, userID, answer string) (*evaluation, error) {
+	question, err := b.db.GetQuestion(quiz.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	assist, err := b.db.GetAssist(userID, quiz.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := b.db.GetLastAnswer(userID, quiz.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.gradeAnswer(question, answer)
+	if err != nil {
+		return nil, err
+	}
+
+	if assist.HintUsed {
+		result.Score = max(result.Score-hintPenalty, 0)
+	}
+
+	if question.ExerciseType == string(claude.ExerciseVocab) {
+		b.recordVocabAnswer(userID, question, result)
+		return &evaluation{result: result, assist: assist}, nil
+	}
+
+	b.saveAnswer(userID, question, answer, result)
+
+	b.collectVocabulary(userID, question, result)
+
+	// Update the spaced-repetition schedule for this question
+	if !assist.Revealed {
+		if err := b.db.RecordReview(userID, quiz.QuestionID, result.Score); err != nil {
+			log.Printf("Error recording review: %v", err)
+		}
+	}
+
+	// The evaluation shows the model answer, so answering again is practice
+	if err := b.db.RecordAnswerSeen(userID, question.GuildID, quiz.QuestionID); err != nil {
+		log.Printf("Error recording answer seen: %v", err)
+	}
+
+	level, err := b.db.AdjustLevel(userID, question.GuildID)
+	if err != nil {
+		log.Printf("Error adjusting level: %v", err)
+	}
+
+	return &evaluation{result: result, assist: assist, previous: previous, level: level, recorded: !assist.Revealed}, nil
+}
+
+// saveAnswer records a graded answer in the guild the question was asked in,
+// together with its classified errors
+func (b *Bot) saveAnswer(userID string, question *db.Question, answer string, result *claude.EvaluationResult) {
+	answerID, err := b.db.SaveAnswer(userID, question.GuildID, question.ID, answer, result.ModelAnswer, result.Score, result.Feedback)
+	if err != nil {
+		log.Printf("Error saving answer: %v", err)
+		return
+	}
+
+	// Corrections of Japanese translations do not fit the English error categories
+	if question.Direction == db.DirectionEnJa {
+		return
+	}
+
+	errs := make([]db.AnswerError, len(result.Corrections))
+	for n, c := range result.Corrections {
+		errs[n] = db.AnswerError{Category: c.Category, Span: c.Span, Suggestion: c.Suggestion}
+	}
+	if err := b.db.SaveAnswerErrors(answerID, errs); err != nil {
+		log.Printf("Error saving answer errors: %v", err)
+	}
 }
 
-// extractQuestionID extracts question ID from title like "📝 英作文問題 #42"
-func extractQuestionID(title string) int64 {
-	re := regexp.MustCompile(`#(\d+)`)
-	matches := re.FindStringSubmatch(title)
-	if len(matches) < 2 {
-		return 0
+// gradeAnswer grades an answer to a question. Closed exercises are checked
+// against their answer key; translations and free text exercises go to the
+// model, which explains in the locale of the question's guild.
+func (b *Bot) gradeAnswer(question *db.Question, answer string) (*claude.EvaluationResult, error) {
+	ctx := b.modelContext(question.GuildID)
+	if question.ExerciseType == db.ExerciseTranslation {
+		return b.model.EvaluateAnswer(ctx, question.Japanese, answer, claude.Direction(question.Direction))
 	}
-	id, err := strconv.ParseInt(matches[1], 10, 64)
+
+	exercise := exerciseFromQuestion(question)
+	if result, ok := claude.GradeExercise(exercise, answer); ok {
+		return result, nil
+	}
+	return b.model.EvaluateExercise(ctx, exercise, answer)
+}
+
+// announceStreakMilestone congratulates the user when their first counted
+// answer of the day, in any server, brings their streak to a milestone
+func (b *Bot) announceStreakMilestone(channelID, guildID, userID string) {
+	stats, err := b.db.GetUserStats(userID, guildID, db.ScopeGlobal)
+	if err != nil {
+		log.Printf("Error getting stats: %v", err)
+		return
+	}
+
+	if stats.AnswersToday != 1 || !db.IsStreakMilestone(stats.CurrentStreak) {
+		return
+	}
+
+	_, err = b.session.ChannelMessageSend(channelID, fmt.Sprintf("🔥 <@%s> %d日連続で回答しました！おめでとうございます🎉", userID, stats.CurrentStreak))
 	if err != nil {
-		return 0
+		log.Printf("Error sending streak milestone: %v", err)
 	}
-	return id
 }
 
-// createEvaluationEmbed creates the evaluation response embed
-func (b *Bot) createEvaluationEmbed(userAnswer string, score int, feedback, modelAnswer string) *discordgo.MessageEmbed {
+// createEvaluationEmbed creates the evaluation response embed, comparing the
+// score with the previous attempt and noting any help the user took
+func (b *Bot) createEvaluationEmbed(userAnswer string, eval *evaluation) *discordgo.MessageEmbed {
+	result, assist := eval.result, eval.assist
+	score := result.Score
+
 	// Choose color based on score
 	var color int
 	var emoji string
@@ -112,27 +272,86 @@ func (b *Bot) createEvaluationEmbed(userAnswer string, score int, feedback, mode
 		emoji = "💪"
 	}
 
-	return &discordgo.MessageEmbed{
-		Title: fmt.Sprintf("%s 回答評価", emoji),
-		Color: color,
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:  "あなたの回答",
-				Value: userAnswer,
-			},
-			{
-				Name:   "📊 スコア",
-				Value:  fmt.Sprintf("**%d** / 100", score),
-				Inline: true,
-			},
-			{
-				Name:  "📖 模範解答",
-				Value: modelAnswer,
-			},
-			{
-				Name:  "💬 フィードバック",
-				Value: feedback,
-			},
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:  "あなたの回答",
+			Value: userAnswer,
 		},
+		{
+			Name:   "📊 スコア",
+			Value:  fmt.Sprintf("**%d** / 100", score),
+			Inline: true,
+		},
+	}
+
+	if prev := eval.previous; prev != nil {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("🔁 %d回目の挑戦", prev.Attempt+1),
+			Value:  fmt.Sprintf("前回 %d 点 → 今回 %d 点（%s）", prev.Score, score, formatScoreDelta(score-prev.Score)),
+			Inline: true,
+		})
+	}
+
+	fields = append(fields,
+		&discordgo.MessageEmbedField{
+			Name:  "📖 模範解答",
+			Value: result.ModelAnswer,
+		},
+	)
+
+	if len(result.Corrections) > 0 {
+		var lines []string
+		for _, c := range result.Corrections {
+			lines = append(lines, fmt.Sprintf("~~%s~~ → **%s**　%s", c.Span, c.Suggestion, c.Explanation))
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  "✏️ 修正点",
+			Value: truncate(strings.Join(lines, "\n"), 1024),
+		})
+	}
+
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:  "💬 フィードバック",
+		Value: truncate(result.Feedback, 1024),
+	})
+
+	if eval.level != nil {
+		fields = append(fields, levelChangeField(eval.level))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:  fmt.Sprintf("%s 回答評価", emoji),
+		Color:  color,
+		Fields: fields,
+	}
+
+	switch {
+	case assist.Revealed:
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: "👀 模範解答を見た後の回答のため、練習として扱われ統計には記録されません"}
+	case assist.HintUsed:
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("💡 ヒントを使用したため %d 点減点されています", hintPenalty)}
+	}
+
+	return embed
+}
+
+// formatScoreDelta formats the change in score between two attempts
+func formatScoreDelta(delta int) string {
+	switch {
+	case delta > 0:
+		return fmt.Sprintf("📈 +%d", delta)
+	case delta < 0:
+		return fmt.Sprintf("📉 %d", delta)
+	default:
+		return "±0"
+	}
+}
+
+// truncate shortens s to at most max runes so it fits in an embed field
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
 	}
+	return string(runes[:max-1]) + "…"
 }
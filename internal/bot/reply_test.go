@@ -4,24 +4,22 @@ import (
 	"testing"
 )
 
-func TestExtractQuestionID(t *testing.T) {
+func TestTruncate(t *testing.T) {
 	tests := []struct {
-		title    string
-		expected int64
+		input    string
+		max      int
+		expected string
 	}{
-		{"📝 英作文問題 #1", 1},
-		{"📝 英作文問題 #42", 42},
-		{"📝 英作文問題 #123456", 123456},
-		{"Random title", 0},
-		{"No number here", 0},
-		{"#abc not a number", 0},
-		{"", 0},
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"this is too long", 8, "this is…"},
+		{"日本語の文章です", 5, "日本語の…"},
 	}
 
 	for _, tt := range tests {
-		result := extractQuestionID(tt.title)
+		result := truncate(tt.input, tt.max)
 		if result != tt.expected {
-			t.Errorf("For title '%s': expected %d, got %d", tt.title, tt.expected, result)
+			t.Errorf("truncate(%q, %d): expected %q, got %q", tt.input, tt.max, tt.expected, result)
 		}
 	}
 }
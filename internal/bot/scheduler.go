@@ -7,16 +7,24 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+	"github.com/melophe/Discord-ENG/internal/schedule"
 )
 
+// rescanInterval bounds how long the scheduler sleeps so that setting changes are picked up
+const rescanInterval = time.Minute
+
 // Scheduler handles periodic quiz posting
 type Scheduler struct {
 	bot      *Bot
 	interval time.Duration
+	started  time.Time
 	stop     chan struct{}
 }
 
-// NewScheduler creates a new scheduler
+// NewScheduler creates a new scheduler. The interval is used for users who
+// have not set their own delivery schedule.
 func NewScheduler(bot *Bot, intervalMinutes int) *Scheduler {
 	return &Scheduler{
 		bot:      bot,
@@ -27,8 +35,9 @@ func NewScheduler(bot *Bot, intervalMinutes int) *Scheduler {
 
 // Start begins the periodic quiz posting
 func (s *Scheduler) Start() {
+	s.started = time.Now()
 	go s.run()
-	log.Printf("Scheduler started (interval: %v)", s.interval)
+	log.Printf("Scheduler started (default interval: %v)", s.interval)
 }
 
 // Stop stops the scheduler
@@ -37,67 +46,234 @@ func (s *Scheduler) Stop() {
 	log.Println("Scheduler stopped")
 }
 
-// run is the main scheduler loop
+// scheduledJob is a user or guild that receives quizzes on a schedule
+type scheduledJob struct {
+	name     string
+	schedule string
+	timezone string
+	post     func()
+}
+
+// jobs returns the scheduled jobs for all users and guilds with scheduling enabled
+func (s *Scheduler) jobs() []scheduledJob {
+	var jobs []scheduledJob
+
+	users, err := s.bot.db.GetScheduledUsers()
+	if err != nil {
+		log.Printf("Error getting scheduled users: %v", err)
+	}
+	for _, user := range users {
+		user := user
+		jobs = append(jobs, scheduledJob{
+			name:     "user " + user.DiscordID,
+			schedule: user.Schedule,
+			timezone: user.Timezone,
+			post:     func() { s.postScheduledQuiz(user) },
+		})
+	}
+
+	guilds, err := s.bot.db.GetScheduledGuilds()
+	if err != nil {
+		log.Printf("Error getting scheduled guilds: %v", err)
+	}
+	for _, guild := range guilds {
+		guild := guild
+		jobs = append(jobs, scheduledJob{
+			name:     "guild " + guild.GuildID,
+			schedule: guild.Schedule,
+			timezone: guild.Timezone,
+			post:     func() { s.postGuildQuiz(guild) },
+		})
+	}
+
+	return jobs
+}
+
+// run is the main scheduler loop. It sleeps until the earliest upcoming fire
+// time across all jobs, posts quizzes for the jobs due at that time, and
+// then recomputes.
 func (s *Scheduler) run() {
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
+	last := s.started
 
 	for {
+		next := last.Add(rescanInterval)
+		var due []scheduledJob
+		for _, job := range s.jobs() {
+			fire, ok := s.nextFire(job, last)
+			if !ok {
+				continue
+			}
+			switch {
+			case fire.Before(next):
+				next = fire
+				due = []scheduledJob{job}
+			case fire.Equal(next):
+				due = append(due, job)
+			}
+		}
+
+		timer := time.NewTimer(time.Until(next))
 		select {
-		case <-ticker.C:
-			s.postScheduledQuiz()
+		case <-timer.C:
+			if len(due) > 0 {
+				log.Printf("Posting %d scheduled quizzes...", len(due))
+			}
+			for _, job := range due {
+				job.post()
+			}
+			last = next
 		case <-s.stop:
+			timer.Stop()
 			return
 		}
 	}
 }
 
-// postScheduledQuiz posts a quiz to the configured channel
-func (s *Scheduler) postScheduledQuiz() {
-	log.Println("Posting scheduled quiz...")
+// nextFire returns the job's first delivery time after the given time.
+// Jobs without a schedule fall back to the global interval.
+func (s *Scheduler) nextFire(job scheduledJob, after time.Time) (time.Time, bool) {
+	if job.schedule == "" {
+		if s.interval <= 0 {
+			return time.Time{}, false
+		}
+		periods := after.Sub(s.started)/s.interval + 1
+		return s.started.Add(periods * s.interval), true
+	}
+
+	spec, err := schedule.Parse(job.schedule)
+	if err != nil {
+		log.Printf("Invalid schedule for %s: %v", job.name, err)
+		return time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation(job.timezone)
+	if err != nil {
+		loc = time.UTC
+	}
 
-	// Use default settings for scheduled quizzes
-	theme := "日常会話"
-	difficulty := "intermediate"
+	next := spec.Next(after, loc)
+	return next, !next.IsZero()
+}
 
+// postScheduledQuiz posts a quiz generated from the user's settings, either by
+// DM or to their guild's quiz channel mentioning the user
+func (s *Scheduler) postScheduledQuiz(user *db.User) {
 	ctx := context.Background()
-	japanese, err := s.bot.claude.GenerateQuestion(ctx, theme, difficulty)
+	direction := pickDirection(user.Direction)
+	japanese, err := s.bot.model.GenerateQuestion(ctx, user.Theme, user.QuestionDifficulty(), claude.Direction(direction), claude.Focus{})
 	if err != nil {
-		log.Printf("Error generating scheduled question: %v", err)
+		log.Printf("Error generating scheduled question for %s: %v", user.DiscordID, err)
 		return
 	}
 
-	questionID, err := s.bot.db.SaveQuestion(japanese, difficulty, theme)
+	questionID, err := s.bot.db.SaveQuestion(user.GuildID, japanese, user.QuestionDifficulty(), user.Theme, direction)
 	if err != nil {
 		log.Printf("Error saving scheduled question: %v", err)
 		return
 	}
 
-	embed := s.createScheduledQuizEmbed(questionID, japanese, theme, difficulty)
+	embed := s.createScheduledQuizEmbed(questionID, japanese, user.Theme, user.QuestionDifficulty(), direction)
 	components := s.bot.createQuizButtons()
 
-	_, err = s.bot.Session().ChannelMessageSendComplex(s.bot.ChannelID(), &discordgo.MessageSend{
+	channelID := s.quizChannel(user)
+
+	var msg *discordgo.Message
+	if user.Delivery == db.DeliveryDM || channelID == "" {
+		msg, err = s.bot.sendDirectMessage(user.DiscordID, &discordgo.MessageSend{
+			Content:    "⏰ 定期出題です！",
+			Embed:      embed,
+			Components: components,
+		})
+	} else {
+		msg, err = s.bot.Session().ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+			Content:    fmt.Sprintf("<@%s> ⏰ 定期出題です！", user.DiscordID),
+			Embed:      embed,
+			Components: components,
+			AllowedMentions: &discordgo.MessageAllowedMentions{
+				Users: []string{user.DiscordID},
+			},
+		})
+	}
+	if err != nil {
+		log.Printf("Error posting scheduled quiz: %v", err)
+		return
+	}
+	s.bot.recordQuizMessage(msg, questionID, user.DiscordID)
+
+	log.Printf("Scheduled quiz #%d posted for %s", questionID, user.DiscordID)
+}
+
+// quizChannel returns the channel for a user's scheduled quizzes: their
+// guild's quiz channel, else the fallback channel if it is in their guild.
+// Settings from DMs and guilds without a channel have none, so their quizzes
+// go by DM.
+func (s *Scheduler) quizChannel(user *db.User) string {
+	if user.GuildID == "" {
+		return ""
+	}
+	guild, err := s.bot.db.GetGuild(user.GuildID)
+	if err != nil {
+		log.Printf("Error getting guild %s: %v", user.GuildID, err)
+	} else if guild.ChannelID != "" {
+		return guild.ChannelID
+	}
+	return s.bot.fallbackChannel(user.GuildID)
+}
+
+// postGuildQuiz posts a quiz from the guild's defaults to its quiz channel,
+// open for anyone to answer, or a timed challenge if the guild enabled them
+func (s *Scheduler) postGuildQuiz(guild *db.Guild) {
+	ctx := context.Background()
+	japanese, err := s.bot.model.GenerateQuestion(ctx, guild.Theme, guild.Difficulty, claude.JapaneseToEnglish, claude.Focus{})
+	if err != nil {
+		log.Printf("Error generating scheduled question for guild %s: %v", guild.GuildID, err)
+		return
+	}
+
+	questionID, err := s.bot.db.SaveQuestion(guild.GuildID, japanese, guild.Difficulty, guild.Theme, db.DirectionJaEn)
+	if err != nil {
+		log.Printf("Error saving scheduled question: %v", err)
+		return
+	}
+
+	if guild.ChallengeMinutes > 0 {
+		endsAt := time.Now().Add(time.Duration(guild.ChallengeMinutes) * time.Minute)
+		msg, err := s.bot.Session().ChannelMessageSendComplex(guild.ChannelID, &discordgo.MessageSend{
+			Content:    "⏰ 定期チャレンジです！締切までに回答してください",
+			Embed:      s.bot.createChallengeEmbed(questionID, japanese, guild.Theme, guild.Difficulty, endsAt),
+			Components: createChallengeButtons(),
+		})
+		if err != nil {
+			log.Printf("Error posting scheduled challenge to guild %s: %v", guild.GuildID, err)
+			return
+		}
+		s.bot.startChallenge(msg, guild.GuildID, questionID, endsAt)
+
+		log.Printf("Scheduled challenge #%d posted to guild %s", questionID, guild.GuildID)
+		return
+	}
+
+	embed := s.createScheduledQuizEmbed(questionID, japanese, guild.Theme, guild.Difficulty, db.DirectionJaEn)
+	msg, err := s.bot.Session().ChannelMessageSendComplex(guild.ChannelID, &discordgo.MessageSend{
+		Content:    "⏰ 定期出題です！誰でも回答できます",
 		Embed:      embed,
-		Components: components,
+		Components: s.bot.createQuizButtons(),
 	})
 	if err != nil {
-		log.Printf("Error posting scheduled quiz: %v", err)
+		log.Printf("Error posting scheduled quiz to guild %s: %v", guild.GuildID, err)
 		return
 	}
+	s.bot.recordQuizMessage(msg, questionID, "")
 
-	log.Printf("Scheduled quiz #%d posted successfully", questionID)
+	log.Printf("Scheduled quiz #%d posted to guild %s", questionID, guild.GuildID)
 }
 
 // createScheduledQuizEmbed creates an embed for scheduled quizzes
-func (s *Scheduler) createScheduledQuizEmbed(questionID int64, japanese, theme, difficulty string) *discordgo.MessageEmbed {
-	difficultyLabel := map[string]string{
-		"beginner":     "初級",
-		"intermediate": "中級",
-		"advanced":     "上級",
-	}[difficulty]
+func (s *Scheduler) createScheduledQuizEmbed(questionID int64, japanese, theme, difficulty, direction string) *discordgo.MessageEmbed {
+	difficultyLabel := difficultyName(difficulty)
 
 	return &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("📝 英作文問題 #%d", questionID),
+		Title:       fmt.Sprintf("📝 %s #%d", quizKind(direction), questionID),
 		Description: fmt.Sprintf("「%s」", japanese),
 		Color:       0x5865F2,
 		Fields: []*discordgo.MessageEmbedField{
@@ -105,7 +281,7 @@ func (s *Scheduler) createScheduledQuizEmbed(questionID int64, japanese, theme,
 			{Name: "📊 難易度", Value: difficultyLabel, Inline: true},
 		},
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "💡 このメッセージに返信して回答してください！",
+			Text: "💡 「回答する」ボタンか、このメッセージへの返信で回答してください！",
 		},
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+func TestSchedulerNextFire(t *testing.T) {
+	started := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC) // Monday
+	s := &Scheduler{interval: time.Hour, started: started}
+
+	tests := []struct {
+		name     string
+		job      scheduledJob
+		after    time.Time
+		expected time.Time
+		ok       bool
+	}{
+		{
+			name:     "interval fallback",
+			job:      scheduledJob{name: "user 1"},
+			after:    started.Add(90 * time.Minute),
+			expected: started.Add(2 * time.Hour),
+			ok:       true,
+		},
+		{
+			name:     "interval fallback on boundary",
+			job:      scheduledJob{name: "user 1"},
+			after:    started.Add(time.Hour),
+			expected: started.Add(2 * time.Hour),
+			ok:       true,
+		},
+		{
+			name:     "user schedule in their time zone",
+			job:      scheduledJob{name: "user 2", schedule: "weekdays 07:30", timezone: "America/New_York"},
+			after:    started,
+			expected: time.Date(2025, 1, 6, 12, 30, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name: "invalid schedule is skipped",
+			job:  scheduledJob{name: "guild 3", schedule: "whenever", timezone: "UTC"},
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := s.nextFire(tt.job, tt.after)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok %v, got %v", tt.ok, ok)
+			}
+			if ok && !got.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPostScheduledQuiz_FallbackChannel(t *testing.T) {
+	tests := []struct {
+		name        string
+		guildID     string
+		wantChannel string
+	}{
+		{"guild of the fallback channel", testGuildID, testChannelID},
+		{"other guild without a channel", "guild-2", "dm-" + testUserID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, session := newTestBot(t)
+			user, _ := b.db.GetOrCreateUser(testUserID, tt.guildID)
+			if user.Delivery != db.DeliveryChannel {
+				t.Fatalf("Expected channel delivery by default, got %q", user.Delivery)
+			}
+
+			NewScheduler(b, 60).postScheduledQuiz(user)
+
+			if quiz := session.lastMessage(); quiz == nil || quiz.ChannelID != tt.wantChannel {
+				t.Errorf("Expected the quiz in %q, got %+v", tt.wantChannel, quiz)
+			}
+		})
+	}
+}
+
+func TestFallbackChannel_LookedUpOnce(t *testing.T) {
+	b, session := newTestBot(t)
+
+	for range 3 {
+		b.fallbackChannel(testGuildID)
+		b.fallbackChannel("guild-2")
+	}
+	if session.lookups != 1 {
+		t.Errorf("Expected the fallback channel looked up once, got %d lookups", session.lookups)
+	}
+}
@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// sentMessage is a message the bot sent to a channel
+type sentMessage struct {
+	ChannelID  string
+	Content    string
+	Embeds     []*discordgo.MessageEmbed
+	Components []discordgo.MessageComponent
+}
+
+// recordingSession is a Session that records everything the bot sends
+type recordingSession struct {
+	mu        sync.Mutex
+	nextID    int
+	responses []*discordgo.InteractionResponse
+	edits     []*discordgo.WebhookEdit
+	sent      []sentMessage
+	messages  []*discordgo.Message
+	reactions []reaction
+	lookups   int // Channel calls
+}
+
+// reaction is an emoji the bot added to a message
+type reaction struct {
+	MessageID string
+	Emoji     string
+}
+
+func (r *recordingSession) newMessage(channelID, content string, embeds []*discordgo.MessageEmbed, components []discordgo.MessageComponent) *discordgo.Message {
+	r.nextID++
+	msg := &discordgo.Message{
+		ID:         fmt.Sprintf("msg-%d", r.nextID),
+		ChannelID:  channelID,
+		Content:    content,
+		Embeds:     embeds,
+		Components: components,
+		Author:     &discordgo.User{ID: testBotUserID, Bot: true},
+	}
+	r.messages = append(r.messages, msg)
+	return msg
+}
+
+func (r *recordingSession) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses = append(r.responses, resp)
+	return nil
+}
+
+func (r *recordingSession) InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.edits = append(r.edits, newresp)
+
+	var content string
+	var embeds []*discordgo.MessageEmbed
+	var components []discordgo.MessageComponent
+	if newresp.Content != nil {
+		content = *newresp.Content
+	}
+	if newresp.Embeds != nil {
+		embeds = *newresp.Embeds
+	}
+	if newresp.Components != nil {
+		components = *newresp.Components
+	}
+	return r.newMessage(interaction.ChannelID, content, embeds, components), nil
+}
+
+func (r *recordingSession) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return r.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Content: content})
+}
+
+func (r *recordingSession) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return r.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}})
+}
+
+func (r *recordingSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	embeds := data.Embeds
+	if data.Embed != nil {
+		embeds = append([]*discordgo.MessageEmbed{data.Embed}, embeds...)
+	}
+	r.sent = append(r.sent, sentMessage{
+		ChannelID:  channelID,
+		Content:    data.Content,
+		Embeds:     embeds,
+		Components: data.Components,
+	})
+	return r.newMessage(channelID, data.Content, embeds, data.Components), nil
+}
+
+func (r *recordingSession) ChannelTyping(channelID string, options ...discordgo.RequestOption) error {
+	return nil
+}
+
+func (r *recordingSession) MessageReactionAdd(channelID, messageID, emojiID string, options ...discordgo.RequestOption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reactions = append(r.reactions, reaction{MessageID: messageID, Emoji: emojiID})
+	return nil
+}
+
+func (r *recordingSession) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return &discordgo.Channel{ID: "dm-" + recipientID, Type: discordgo.ChannelTypeDM}, nil
+}
+
+// Channel looks up a channel. Every channel but DMs is in the test guild.
+func (r *recordingSession) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	r.mu.Lock()
+	r.lookups++
+	r.mu.Unlock()
+
+	if strings.HasPrefix(channelID, "dm-") {
+		return &discordgo.Channel{ID: channelID, Type: discordgo.ChannelTypeDM}, nil
+	}
+	return &discordgo.Channel{ID: channelID, GuildID: testGuildID, Type: discordgo.ChannelTypeGuildText}, nil
+}
+
+// lastResponse returns the most recent interaction response
+func (r *recordingSession) lastResponse() *discordgo.InteractionResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.responses) == 0 {
+		return nil
+	}
+	return r.responses[len(r.responses)-1]
+}
+
+// lastMessage returns the most recent message the bot created
+func (r *recordingSession) lastMessage() *discordgo.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.messages) == 0 {
+		return nil
+	}
+	return r.messages[len(r.messages)-1]
+}
+
+// lastEdit returns the most recent interaction response edit
+func (r *recordingSession) lastEdit() *discordgo.WebhookEdit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.edits) == 0 {
+		return nil
+	}
+	return r.edits[len(r.edits)-1]
+}
@@ -0,0 +1,210 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+	"github.com/melophe/Discord-ENG/internal/schedule"
+)
+
+// setupCommand is the /setup command server admins use to configure the bot
+var setupCommand = &discordgo.ApplicationCommand{
+	Name:                     "setup",
+	Description:              "Configure the quiz channel and defaults for this server",
+	DefaultMemberPermissions: func() *int64 { p := int64(discordgo.PermissionManageGuild); return &p }(),
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:         discordgo.ApplicationCommandOptionChannel,
+			Name:         "channel",
+			Description:  "Channel where scheduled quizzes are posted",
+			ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "theme",
+			Description: "Default theme for new members",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "difficulty",
+			Description: "Default difficulty for new members",
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "初級 (Beginner)", Value: "beginner"},
+				{Name: "中級 (Intermediate)", Value: "intermediate"},
+				{Name: "上級 (Advanced)", Value: "advanced"},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "scheduled",
+			Description: "Post scheduled quizzes to the channel",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "schedule",
+			Description: "When to post, e.g. \"weekdays 08:00\" (\"-\" for the default interval)",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "timezone",
+			Description: "Server time zone, e.g. Asia/Tokyo",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "challenge_minutes",
+			Description: "Post scheduled quizzes as challenges open this many minutes (0 to disable)",
+			MinValue:    func() *float64 { v := 0.0; return &v }(),
+			MaxValue:    60,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "locale",
+			Description: "Language of feedback, explanations and hints",
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "日本語 (Japanese)", Value: claude.LocaleJapanese},
+				{Name: "English", Value: claude.LocaleEnglish},
+			},
+		},
+	},
+}
+
+// localeLabels names each locale
+var localeLabels = map[string]string{
+	claude.LocaleJapanese: "日本語",
+	claude.LocaleEnglish:  "English",
+}
+
+// handleSetupCommand updates the server's configuration from the given options
+// and shows the result
+func (b *Bot) handleSetupCommand(i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		b.respondComponentMessage(i, "❌ /setup はサーバー内でのみ使用できます")
+		return
+	}
+	if i.Member == nil || i.Member.Permissions&discordgo.PermissionManageGuild == 0 {
+		b.respondComponentMessage(i, "❌ /setup にはサーバー管理権限が必要です")
+		return
+	}
+
+	guild, err := b.db.GetGuild(i.GuildID)
+	if err != nil {
+		b.respondComponentMessage(i, "サーバー設定の取得に失敗しました")
+		return
+	}
+
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "channel":
+			guild.ChannelID = opt.ChannelValue(nil).ID
+		case "theme":
+			theme := strings.TrimSpace(opt.StringValue())
+			if theme == "" {
+				b.respondComponentMessage(i, "❌ テーマを入力してください")
+				return
+			}
+			guild.Theme = theme
+		case "difficulty":
+			guild.Difficulty = opt.StringValue()
+		case "scheduled":
+			guild.ScheduleEnabled = opt.BoolValue()
+		case "schedule":
+			spec := strings.TrimSpace(opt.StringValue())
+			if spec == "-" {
+				spec = ""
+			}
+			if spec != "" {
+				if _, err := schedule.Parse(spec); err != nil {
+					b.respondComponentMessage(i, fmt.Sprintf("❌ 配信時刻の形式が正しくありません: %v", err))
+					return
+				}
+			}
+			guild.Schedule = spec
+		case "timezone":
+			timezone := strings.TrimSpace(opt.StringValue())
+			if _, err := time.LoadLocation(timezone); err != nil || timezone == "" {
+				b.respondComponentMessage(i, fmt.Sprintf("❌ タイムゾーン「%s」が見つかりません（例: Asia/Tokyo）", timezone))
+				return
+			}
+			guild.Timezone = timezone
+		case "challenge_minutes":
+			guild.ChallengeMinutes = int(opt.IntValue())
+		case "locale":
+			guild.Locale = opt.StringValue()
+		}
+	}
+
+	if guild.ScheduleEnabled && guild.ChannelID == "" {
+		b.respondComponentMessage(i, "❌ 定期出題を有効にするには channel を指定してください")
+		return
+	}
+
+	if err := b.db.SaveGuild(guild); err != nil {
+		b.respondComponentMessage(i, "サーバー設定の更新に失敗しました")
+		return
+	}
+
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{b.createGuildSettingsEmbed(guild)},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// createGuildSettingsEmbed shows a server's quiz configuration
+func (b *Bot) createGuildSettingsEmbed(guild *db.Guild) *discordgo.MessageEmbed {
+	difficultyLabel := difficultyName(guild.Difficulty)
+
+	channelLabel := "未設定"
+	if guild.ChannelID != "" {
+		channelLabel = fmt.Sprintf("<#%s>", guild.ChannelID)
+	}
+
+	challengeLabel := "OFF"
+	if guild.ChallengeMinutes > 0 {
+		challengeLabel = fmt.Sprintf("%d分", guild.ChallengeMinutes)
+	}
+
+	scheduleLabel := guild.Schedule
+	if scheduleLabel == "" {
+		scheduleLabel = fmt.Sprintf("%d分ごと", b.config.Schedule.IntervalMinutes)
+	}
+
+	localeLabel, ok := localeLabels[guild.Locale]
+	if !ok {
+		localeLabel = localeLabels[claude.LocaleJapanese]
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: "🛠️ サーバー設定",
+		Color: 0x5865F2,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "出題チャンネル", Value: channelLabel, Inline: true},
+			{Name: "既定の難易度", Value: difficultyLabel, Inline: true},
+			{Name: "既定のテーマ", Value: guild.Theme, Inline: true},
+			{Name: "定期出題", Value: map[bool]string{true: "ON", false: "OFF"}[guild.ScheduleEnabled], Inline: true},
+			{Name: "配信時刻", Value: scheduleLabel, Inline: true},
+			{Name: "タイムゾーン", Value: guild.Timezone, Inline: true},
+			{Name: "チャレンジ", Value: challengeLabel, Inline: true},
+			{Name: "解説の言語", Value: localeLabel, Inline: true},
+		},
+	}
+}
+
+// modelContext returns a context asking the model to write feedback,
+// explanations and hints in the locale of the guild a question was asked in
+func (b *Bot) modelContext(guildID string) context.Context {
+	guild, err := b.db.GetGuild(guildID)
+	if err != nil {
+		log.Printf("Error getting guild %s: %v", guildID, err)
+		return context.Background()
+	}
+	return claude.WithLocale(context.Background(), guild.Locale)
+}
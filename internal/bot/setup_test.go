@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// asAdmin gives the interaction's member the Manage Server permission
+func asAdmin(i *discordgo.InteractionCreate) *discordgo.InteractionCreate {
+	i.Member.Permissions = discordgo.PermissionManageGuild
+	return i
+}
+
+func stringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+}
+
+func TestSetupCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		interaction *discordgo.InteractionCreate
+		wantText    string
+	}{
+		{"requires a guild", inDM(asAdmin(slashCommand("setup"))), "サーバー内でのみ"},
+		{"requires manage server", slashCommand("setup"), "管理権限が必要"},
+		{"rejects bad timezone", asAdmin(slashCommand("setup", stringOption("timezone", "Mars/Olympus"))), "タイムゾーン"},
+		{"rejects bad schedule", asAdmin(slashCommand("setup", stringOption("schedule", "whenever"))), "形式が正しくありません"},
+		{"rejects blank theme", asAdmin(slashCommand("setup", stringOption("theme", "   "))), "テーマを入力"},
+		{"schedule needs a channel", asAdmin(slashCommand("setup", &discordgo.ApplicationCommandInteractionDataOption{
+			Name: "scheduled", Type: discordgo.ApplicationCommandOptionBoolean, Value: true,
+		})), "channel を指定"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, session := newTestBot(t)
+
+			b.onInteractionCreate(nil, tt.interaction)
+
+			resp := session.lastResponse()
+			if resp == nil || !strings.Contains(resp.Data.Content, tt.wantText) {
+				t.Fatalf("Expected response containing %q, got %+v", tt.wantText, resp)
+			}
+
+			guild, _ := b.db.GetGuild(testGuildID)
+			if !guild.CreatedAt.IsZero() {
+				t.Errorf("Expected guild to stay unconfigured, got %+v", guild)
+			}
+		})
+	}
+}
+
+func TestSetupCommand_SavesGuild(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, asAdmin(slashCommand("setup",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "channel", Type: discordgo.ApplicationCommandOptionChannel, Value: "quiz-channel"},
+		stringOption("theme", "ビジネス"),
+		stringOption("difficulty", "advanced"),
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "scheduled", Type: discordgo.ApplicationCommandOptionBoolean, Value: true},
+		stringOption("schedule", "weekdays 09:00"),
+		stringOption("timezone", "Europe/London"),
+		stringOption("locale", "en"),
+	)))
+
+	resp := session.lastResponse()
+	if resp == nil || len(resp.Data.Embeds) != 1 || resp.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Fatalf("Expected ephemeral guild settings embed, got %+v", resp)
+	}
+
+	guild, err := b.db.GetGuild(testGuildID)
+	if err != nil {
+		t.Fatalf("GetGuild failed: %v", err)
+	}
+	if guild.ChannelID != "quiz-channel" || guild.Theme != "ビジネス" || guild.Difficulty != "advanced" ||
+		!guild.ScheduleEnabled || guild.Schedule != "weekdays 09:00" || guild.Timezone != "Europe/London" || guild.Locale != "en" {
+		t.Errorf("Unexpected guild settings: %+v", guild)
+	}
+
+	// New members of the guild start from its defaults
+	user, err := b.db.GetOrCreateUser("new-member", testGuildID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUser failed: %v", err)
+	}
+	if user.Theme != "ビジネス" || user.Difficulty != "advanced" || user.Timezone != "Europe/London" {
+		t.Errorf("Expected guild defaults for new member, got %+v", user)
+	}
+}
+
+func TestPostGuildQuiz(t *testing.T) {
+	b, session := newTestBot(t)
+	guild, _ := b.db.GetGuild(testGuildID)
+	guild.ChannelID = "quiz-channel"
+	guild.ScheduleEnabled = true
+	b.db.SaveGuild(guild)
+
+	s := NewScheduler(b, 60)
+	guilds, err := b.db.GetScheduledGuilds()
+	if err != nil || len(guilds) != 1 {
+		t.Fatalf("Expected one scheduled guild, got %v (%v)", guilds, err)
+	}
+	s.postGuildQuiz(guilds[0])
+
+	quiz := session.lastMessage()
+	if quiz == nil || quiz.ChannelID != "quiz-channel" {
+		t.Fatalf("Expected quiz posted to the guild channel, got %+v", quiz)
+	}
+
+	// Anyone in the server may answer a guild quiz
+	reply := replyTo(quiz, "I drink coffee every morning.")
+	reply.Author = &discordgo.User{ID: "someone-else"}
+	b.onMessageCreate(nil, reply)
+
+	if msg := session.lastMessage(); msg == nil || len(msg.Embeds) == 0 || !strings.Contains(msg.Embeds[0].Title, "回答評価") {
+		t.Errorf("Expected evaluation for any member, got %+v", msg)
+	}
+}
@@ -0,0 +1,325 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// vocabSavePrefix starts the custom ID of the button that saves the key
+// phrases of a question to the vocabulary notebook, followed by the ID of the
+// quiz message
+const vocabSavePrefix = "vocab_save:"
+
+// vocabListSize is the number of entries shown by /vocab list
+const vocabListSize = 20
+
+// vocabCommand is the /vocab command
+var vocabCommand = &discordgo.ApplicationCommand{
+	Name:        "vocab",
+	Description: "Manage your vocabulary notebook",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "Show the words and phrases in your notebook",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "add",
+			Description: "Add a word or phrase to your notebook",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "phrase",
+					Description: "The English word or phrase",
+					Required:    true,
+					MaxLength:   100,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "meaning",
+					Description: "Its meaning in Japanese (needed for /vocab quiz)",
+					MaxLength:   100,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "remove",
+			Description: "Remove a word or phrase from your notebook",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "phrase",
+					Description: "The English word or phrase",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "quiz",
+			Description: "Get quizzed on a phrase from your notebook",
+		},
+	},
+}
+
+// createVocabSaveButton creates the button that saves the key phrases of the
+// quiz's question to the vocabulary notebook
+func createVocabSaveButton(quizMessageID string) discordgo.Button {
+	return discordgo.Button{
+		Label:    "📌 単語帳に追加",
+		Style:    discordgo.SecondaryButton,
+		CustomID: vocabSavePrefix + quizMessageID,
+	}
+}
+
+// handleVocabCommand runs a /vocab subcommand
+func (b *Bot) handleVocabCommand(i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+	sub := options[0]
+
+	values := make(map[string]string)
+	for _, opt := range sub.Options {
+		values[opt.Name] = strings.TrimSpace(opt.StringValue())
+	}
+
+	switch sub.Name {
+	case "list":
+		b.handleVocabList(i)
+	case "add":
+		b.handleVocabAdd(i, values["phrase"], values["meaning"])
+	case "remove":
+		b.handleVocabRemove(i, values["phrase"])
+	case "quiz":
+		b.handleVocabQuiz(i)
+	}
+}
+
+// handleVocabList shows the newest entries of the user's notebook
+func (b *Bot) handleVocabList(i *discordgo.InteractionCreate) {
+	entries, err := b.db.GetVocabulary(interactionUserID(i))
+	if err != nil {
+		log.Printf("Error getting vocabulary: %v", err)
+		b.respondComponentMessage(i, "単語帳の取得に失敗しました")
+		return
+	}
+	if len(entries) == 0 {
+		b.respondComponentMessage(i, "📒 単語帳はまだ空です。回答評価の「📌 単語帳に追加」ボタンか /vocab add で追加できます")
+		return
+	}
+
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{b.createVocabEmbed(entries)},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// handleVocabAdd adds a phrase to the user's notebook
+func (b *Bot) handleVocabAdd(i *discordgo.InteractionCreate, phrase, meaning string) {
+	if phrase == "" {
+		b.respondComponentMessage(i, "❌ 語句を入力してください")
+		return
+	}
+
+	added, err := b.db.AddVocabulary(&db.VocabularyEntry{
+		DiscordID: interactionUserID(i),
+		Phrase:    phrase,
+		Meaning:   meaning,
+		Source:    db.VocabManual,
+	})
+	if err != nil {
+		log.Printf("Error adding vocabulary: %v", err)
+		b.respondComponentMessage(i, "単語帳への追加に失敗しました")
+		return
+	}
+	if !added {
+		b.respondComponentMessage(i, fmt.Sprintf("📒 「%s」はすでに単語帳にあります", phrase))
+		return
+	}
+	b.respondComponentMessage(i, fmt.Sprintf("📌 「%s」を単語帳に追加しました", phrase))
+}
+
+// handleVocabRemove removes a phrase from the user's notebook
+func (b *Bot) handleVocabRemove(i *discordgo.InteractionCreate, phrase string) {
+	removed, err := b.db.RemoveVocabulary(interactionUserID(i), phrase)
+	if err != nil {
+		log.Printf("Error removing vocabulary: %v", err)
+		b.respondComponentMessage(i, "単語帳からの削除に失敗しました")
+		return
+	}
+	if !removed {
+		b.respondComponentMessage(i, fmt.Sprintf("❌ 「%s」は単語帳にありません", phrase))
+		return
+	}
+	b.respondComponentMessage(i, fmt.Sprintf("🗑️ 「%s」を単語帳から削除しました", phrase))
+}
+
+// handleVocabQuiz asks for a random phrase from the user's notebook given its
+// meaning. Answers are graded against the phrase without the model.
+func (b *Bot) handleVocabQuiz(i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
+	entry, err := b.db.GetRandomVocabulary(userID)
+	if err != nil {
+		log.Printf("Error getting vocabulary: %v", err)
+		b.respondComponentMessage(i, "単語帳の取得に失敗しました")
+		return
+	}
+	if entry == nil {
+		b.respondComponentMessage(i, "📒 出題できる語句がありません。意味の登録された語句が単語帳に必要です")
+		return
+	}
+
+	user, err := b.db.GetOrCreateUser(userID, i.GuildID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		b.respondComponentMessage(i, "エラーが発生しました")
+		return
+	}
+
+	private := deliversPrivately(i, user)
+	b.deferResponse(i, private)
+
+	question := &db.Question{
+		GuildID:      i.GuildID,
+		Japanese:     entry.Meaning,
+		Difficulty:   user.QuestionDifficulty(),
+		Theme:        "単語帳",
+		ExerciseType: string(claude.ExerciseVocab),
+		AnswerKey:    entry.Phrase,
+	}
+	question.ID, err = b.db.SaveExercise(question)
+	if err != nil {
+		log.Printf("Error saving exercise: %v", err)
+		b.respondError(i, "問題の保存に失敗しました")
+		return
+	}
+
+	b.sendQuiz(i, private, question.ID, "", b.createExerciseEmbed(question), b.createExerciseComponents(question))
+}
+
+// recordVocabAnswer reschedules the notebook entry a vocabulary quiz asked
+// about, so that drilling the notebook stays out of stats, the leaderboard,
+// reviews and levels
+func (b *Bot) recordVocabAnswer(userID string, question *db.Question, result *claude.EvaluationResult) {
+	entry, err := b.db.FindVocabulary(userID, question.AnswerKey)
+	if err != nil {
+		log.Printf("Error getting vocabulary: %v", err)
+		return
+	}
+	if entry == nil {
+		return
+	}
+	if err := b.db.RecordFlashcard(userID, entry.ID, result.Score == 100); err != nil {
+		log.Printf("Error recording flashcard: %v", err)
+	}
+}
+
+// handleVocabSaveButton saves the key phrases of the quiz's question to the
+// user's notebook
+func (b *Bot) handleVocabSaveButton(i *discordgo.InteractionCreate, quizMessageID string) {
+	quiz := b.quizForMessage(i, quizMessageID)
+	if quiz == nil {
+		return
+	}
+
+	phrases, err := b.db.GetKeyPhrases(quiz.QuestionID)
+	if err != nil {
+		log.Printf("Error getting key phrases: %v", err)
+		b.respondComponentMessage(i, "単語帳への追加に失敗しました")
+		return
+	}
+	if len(phrases) == 0 {
+		b.respondComponentMessage(i, "📒 この問題には保存できる語句がありません")
+		return
+	}
+
+	var lines []string
+	for _, k := range phrases {
+		added, err := b.db.AddVocabulary(&db.VocabularyEntry{
+			DiscordID:  interactionUserID(i),
+			Phrase:     k.Phrase,
+			Meaning:    k.Meaning,
+			Source:     db.VocabFromKeyPhrase,
+			QuestionID: quiz.QuestionID,
+		})
+		if err != nil {
+			log.Printf("Error adding vocabulary: %v", err)
+			b.respondComponentMessage(i, "単語帳への追加に失敗しました")
+			return
+		}
+		status := "追加しました"
+		if !added {
+			status = "追加済み"
+		}
+		lines = append(lines, fmt.Sprintf("• **%s** %s（%s）", k.Phrase, k.Meaning, status))
+	}
+
+	b.respondComponentMessage(i, "📌 単語帳に追加しました\n"+strings.Join(lines, "\n"))
+}
+
+// collectVocabulary caches the key phrases of an evaluated question and adds
+// the vocabulary corrections of an English answer to the user's notebook
+func (b *Bot) collectVocabulary(userID string, question *db.Question, result *claude.EvaluationResult) {
+	if len(result.KeyPhrases) > 0 {
+		phrases := make([]db.KeyPhrase, len(result.KeyPhrases))
+		for n, k := range result.KeyPhrases {
+			phrases[n] = db.KeyPhrase{Phrase: k.Phrase, Meaning: k.Meaning}
+		}
+		if err := b.db.SaveKeyPhrases(question.ID, phrases); err != nil {
+			log.Printf("Error saving key phrases: %v", err)
+		}
+	}
+
+	// Corrections of Japanese translations are Japanese
+	if question.Direction == db.DirectionEnJa {
+		return
+	}
+	for _, c := range result.Corrections {
+		if c.Category != "vocabulary" {
+			continue
+		}
+		_, err := b.db.AddVocabulary(&db.VocabularyEntry{
+			DiscordID:  userID,
+			Phrase:     strings.TrimSpace(c.Suggestion),
+			Note:       fmt.Sprintf("%s → %s: %s", c.Span, c.Suggestion, c.Explanation),
+			Source:     db.VocabFromCorrection,
+			QuestionID: question.ID,
+		})
+		if err != nil {
+			log.Printf("Error adding vocabulary: %v", err)
+		}
+	}
+}
+
+// createVocabEmbed lists the newest entries of a vocabulary notebook
+func (b *Bot) createVocabEmbed(entries []*db.VocabularyEntry) *discordgo.MessageEmbed {
+	var lines []string
+	for _, e := range entries[:min(len(entries), vocabListSize)] {
+		line := fmt.Sprintf("• **%s**", e.Phrase)
+		if e.Meaning != "" {
+			line += " — " + e.Meaning
+		} else if e.Note != "" {
+			line += " — " + truncate(e.Note, 60)
+		}
+		lines = append(lines, line)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       "📒 単語帳",
+		Description: truncate(strings.Join(lines, "\n"), 4096),
+		Color:       0x5865F2,
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("全 %d 件（新しい順に最大 %d 件を表示）", len(entries), vocabListSize)},
+	}
+}
@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// vocabCommandOf builds a /vocab subcommand with string options
+func vocabCommandOf(sub string, values ...string) *discordgo.InteractionCreate {
+	option := &discordgo.ApplicationCommandInteractionDataOption{
+		Name: sub,
+		Type: discordgo.ApplicationCommandOptionSubCommand,
+	}
+	for n := 0; n+1 < len(values); n += 2 {
+		option.Options = append(option.Options, &discordgo.ApplicationCommandInteractionDataOption{
+			Name:  values[n],
+			Type:  discordgo.ApplicationCommandOptionString,
+			Value: values[n+1],
+		})
+	}
+	return slashCommand("vocab", option)
+}
+
+// correctingModel is a fake model that flags a vocabulary mistake in every answer
+type correctingModel struct {
+	*claude.Fake
+}
+
+func (m correctingModel) EvaluateAnswer(ctx context.Context, sentence, userAnswer string, direction claude.Direction) (*claude.EvaluationResult, error) {
+	result, err := m.Fake.EvaluateAnswer(ctx, sentence, userAnswer, direction)
+	if err != nil {
+		return nil, err
+	}
+	result.Corrections = append(result.Corrections,
+		claude.Correction{Category: "vocabulary", Span: "take", Suggestion: "drink", Explanation: "飲み物には drink を使います"},
+		claude.Correction{Category: "grammar", Span: "drinks", Suggestion: "drink", Explanation: "主語が I です"},
+	)
+	return result, nil
+}
+
+func TestVocab_SaveKeyPhrases(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	quiz := session.lastMessage()
+
+	b.onInteractionCreate(nil, modalSubmit(answerModalPrefix+quiz.ID, map[string]string{
+		"answer_input": "I drink coffee.",
+	}))
+
+	edit := session.lastEdit()
+	save := (*edit.Components)[0].(discordgo.ActionsRow).Components[1].(discordgo.Button)
+	if save.CustomID != vocabSavePrefix+quiz.ID {
+		t.Fatalf("Expected a save button for the quiz, got %+v", save)
+	}
+
+	b.onInteractionCreate(nil, componentClick(save.CustomID))
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "every morning") || resp.Data.Flags != discordgo.MessageFlagsEphemeral {
+		t.Errorf("Expected the saved key phrase, got %+v", resp.Data)
+	}
+
+	b.onInteractionCreate(nil, componentClick(save.CustomID))
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "追加済み") {
+		t.Errorf("Expected the phrase to be saved once, got %q", resp.Data.Content)
+	}
+
+	entries, _ := b.db.GetVocabulary(testUserID)
+	if len(entries) != 1 || entries[0].Meaning != "毎朝" || entries[0].Source != db.VocabFromKeyPhrase {
+		t.Errorf("Unexpected notebook: %+v", entries)
+	}
+}
+
+func TestVocab_FromCorrections(t *testing.T) {
+	b, session := newTestBot(t)
+	b.model = correctingModel{claude.NewFake()}
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	b.onMessageCreate(nil, replyTo(session.lastMessage(), "I take coffee."))
+
+	entries, _ := b.db.GetVocabulary(testUserID)
+	if len(entries) != 1 || entries[0].Phrase != "drink" || !strings.Contains(entries[0].Note, "take → drink") {
+		t.Errorf("Expected only the vocabulary correction, got %+v", entries)
+	}
+}
+
+func TestVocab_Commands(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, vocabCommandOf("list"))
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "まだ空") {
+		t.Errorf("Expected an empty notebook, got %+v", resp.Data)
+	}
+
+	b.onInteractionCreate(nil, vocabCommandOf("add", "phrase", "look forward to", "meaning", "楽しみにする"))
+	b.onInteractionCreate(nil, vocabCommandOf("add", "phrase", "by the way"))
+	b.onInteractionCreate(nil, vocabCommandOf("add", "phrase", "Look forward to"))
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "すでに") {
+		t.Errorf("Expected a duplicate warning, got %q", resp.Data.Content)
+	}
+
+	b.onInteractionCreate(nil, vocabCommandOf("list"))
+	embed := session.lastResponse().Data.Embeds[0]
+	if !strings.HasPrefix(embed.Description, "• **by the way**\n• **look forward to** — 楽しみにする") || !strings.HasPrefix(embed.Footer.Text, "全 2 件") {
+		t.Errorf("Unexpected list: %q %q", embed.Description, embed.Footer.Text)
+	}
+
+	b.onInteractionCreate(nil, vocabCommandOf("remove", "phrase", "by the way"))
+	b.onInteractionCreate(nil, vocabCommandOf("remove", "phrase", "by the way"))
+	if resp := session.lastResponse(); !strings.Contains(resp.Data.Content, "ありません") {
+		t.Errorf("Expected the phrase to be gone, got %q", resp.Data.Content)
+	}
+
+	// Only the entry with a meaning can be quizzed, and it is graded locally
+	b.onInteractionCreate(nil, vocabCommandOf("quiz"))
+	quiz := session.lastMessage()
+	if embed := quiz.Embeds[0]; !strings.Contains(embed.Title, "単語帳クイズ") || !strings.Contains(embed.Description, "楽しみにする") {
+		t.Fatalf("Expected a vocabulary quiz, got %+v", embed)
+	}
+	b.model = offlineModel{claude.NewFake()}
+	b.onMessageCreate(nil, replyTo(quiz, "look forward to"))
+	if score := session.lastMessage().Embeds[0].Fields[1].Value; score != "**100** / 100" {
+		t.Errorf("Expected the vocabulary answer graded correct, got %q", score)
+	}
+
+	// The answer reschedules the entry but stays out of stats
+	if due, _ := b.db.CountDueFlashcards(testUserID); due != 0 {
+		t.Errorf("Expected the entry rescheduled, got %d due", due)
+	}
+	stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer)
+	if stats.TotalAnswers != 0 {
+		t.Errorf("Expected the vocabulary answer kept out of stats, got %+v", stats)
+	}
+}
@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+// weaknessLimit is the number of error categories shown by /weaknesses
+const weaknessLimit = 5
+
+// errorCategoryLabels names each correction category
+var errorCategoryLabels = map[string]string{
+	"article":     "冠詞",
+	"tense":       "時制",
+	"preposition": "前置詞",
+	"word_order":  "語順",
+	"agreement":   "主語と動詞の一致・単複",
+	"vocabulary":  "語の選択",
+	"spelling":    "つづり",
+	"grammar":     "その他の文法",
+	"naturalness": "自然さ",
+	"other":       "その他",
+}
+
+// weaknessesCommand is the /weaknesses command
+var weaknessesCommand = &discordgo.ApplicationCommand{
+	Name:        "weaknesses",
+	Description: "See which kinds of mistakes you make most often",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "scope",
+			Description: "Count answers from this server only or from all servers",
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "このサーバー (server)", Value: string(db.ScopeServer)},
+				{Name: "全サーバー (global)", Value: string(db.ScopeGlobal)},
+			},
+		},
+	},
+}
+
+// handleWeaknessesCommand shows the user's most frequent error categories
+func (b *Bot) handleWeaknessesCommand(i *discordgo.InteractionCreate) {
+	scope := db.ScopeServer
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "scope" {
+			scope = db.StatsScope(opt.StringValue())
+		}
+	}
+
+	weaknesses, err := b.db.GetWeaknesses(interactionUserID(i), i.GuildID, scope, weaknessLimit)
+	if err != nil {
+		log.Printf("Error getting weaknesses: %v", err)
+		b.respondComponentMessage(i, "苦手分析の取得に失敗しました")
+		return
+	}
+
+	var components []discordgo.MessageComponent
+	if len(weaknesses) > 0 {
+		components = []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "🎯 苦手克服",
+						Style:    discordgo.PrimaryButton,
+						CustomID: weakQuizPrefix + string(scope),
+					},
+				},
+			},
+		}
+	}
+
+	b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{b.createWeaknessesEmbed(weaknesses, scope)},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// createWeaknessesEmbed lists error categories with their trend over the last
+// two 14-day periods and an example of the latest error
+func (b *Bot) createWeaknessesEmbed(weaknesses []db.Weakness, scope db.StatsScope) *discordgo.MessageEmbed {
+	scopeLabel := "このサーバー"
+	if scope == db.ScopeGlobal {
+		scopeLabel = "全サーバー"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:  "🩺 苦手分析",
+		Color:  0xFF6B6B,
+		Footer: &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("集計範囲: %s ・ 直近14日間とその前の14日間を比較", scopeLabel)},
+	}
+	if len(weaknesses) == 0 {
+		embed.Description = "まだ記録された誤りがありません。/quiz で問題に挑戦しましょう！"
+		return embed
+	}
+
+	for n, w := range weaknesses {
+		label, ok := errorCategoryLabels[w.Category]
+		if !ok {
+			label = w.Category
+		}
+
+		lines := []string{fmt.Sprintf("合計 **%d** 回 ・ 直近14日 %d 回（前の14日 %d 回）%s", w.Total, w.Recent, w.Previous, weaknessTrend(w))}
+		if w.Example.Span != "" || w.Example.Suggestion != "" {
+			lines = append(lines, fmt.Sprintf("最近の例: ~~%s~~ → **%s**", w.Example.Span, w.Example.Suggestion))
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%d. %s", n+1, label),
+			Value: truncate(strings.Join(lines, "\n"), 1024),
+		})
+	}
+	return embed
+}
+
+// weaknessTrend marks whether errors of a category became more or less frequent
+func weaknessTrend(w db.Weakness) string {
+	switch {
+	case w.Recent > w.Previous:
+		return " 📈"
+	case w.Recent < w.Previous:
+		return " 📉 改善中"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/melophe/Discord-ENG/internal/claude"
+	"github.com/melophe/Discord-ENG/internal/db"
+)
+
+func TestWeaknesses(t *testing.T) {
+	b, session := newTestBot(t)
+
+	b.onInteractionCreate(nil, slashCommand("weaknesses"))
+	if embed := session.lastResponse().Data.Embeds[0]; !strings.Contains(embed.Description, "まだ記録された誤りがありません") {
+		t.Errorf("Expected no weaknesses yet, got %+v", embed)
+	}
+
+	b.model = correctingModel{claude.NewFake()}
+	for range 2 {
+		b.onInteractionCreate(nil, slashCommand("quiz"))
+		b.onMessageCreate(nil, replyTo(session.lastMessage(), "I take coffee."))
+	}
+
+	b.onInteractionCreate(nil, slashCommand("weaknesses", &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "scope", Type: discordgo.ApplicationCommandOptionString, Value: "global",
+	}))
+
+	resp := session.lastResponse()
+	if resp.Data.Flags != discordgo.MessageFlagsEphemeral {
+		t.Errorf("Expected the analysis to be private")
+	}
+	fields := resp.Data.Embeds[0].Fields
+	// Ties go to the category seen most recently
+	if len(fields) != 2 || fields[0].Name != "1. その他の文法" || fields[1].Name != "2. 語の選択" {
+		t.Fatalf("Expected both categories ranked, got %+v %+v", fields[0], fields[len(fields)-1])
+	}
+	if !strings.Contains(fields[1].Value, "合計 **2** 回") || !strings.Contains(fields[1].Value, "~~take~~ → **drink**") {
+		t.Errorf("Unexpected category details: %q", fields[1].Value)
+	}
+}
+
+func TestWeaknesses_JapaneseAnswers(t *testing.T) {
+	b, session := newTestBot(t)
+	b.model = correctingModel{claude.NewFake()}
+	b.db.GetOrCreateUser(testUserID, testGuildID)
+	b.db.UpdateUserDirection(testUserID, testGuildID, db.DirectionEnJa)
+
+	b.onInteractionCreate(nil, slashCommand("quiz"))
+	b.onMessageCreate(nil, replyTo(session.lastMessage(), "毎朝コーヒーを飲みます。"))
+
+	// Corrections of Japanese answers are not English weak spots
+	if weaknesses, _ := b.db.GetWeaknesses(testUserID, testGuildID, db.ScopeServer, 5); len(weaknesses) != 0 {
+		t.Errorf("Expected no weaknesses from an en→ja answer, got %+v", weaknesses)
+	}
+	if stats, _ := b.db.GetUserStats(testUserID, testGuildID, db.ScopeServer); stats.TotalAnswers != 1 {
+		t.Errorf("Expected the answer itself recorded, got %+v", stats)
+	}
+}
@@ -22,19 +22,46 @@ func NewClient(apiKey, model string) *Client {
 	}
 }
 
-// GenerateQuestion generates a Japanese sentence for English translation practice
-func (c *Client) GenerateQuestion(ctx context.Context, theme, difficulty string) (string, error) {
-	prompt := fmt.Sprintf(GenerateQuestionPrompt, theme, difficulty)
+// GenerateQuestion generates a sentence for translation practice in the given
+// direction, targeting the focus if one is given
+func (c *Client) GenerateQuestion(ctx context.Context, theme, difficulty string, direction Direction, focus Focus) (string, error) {
+	text, err := c.generateText(ctx, fmt.Sprintf(promptsFor(direction).question, theme, difficulty, focus.prompt()), 200)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate question: %w", err)
+	}
+	return text, nil
+}
+
+// GenerateHint generates key vocabulary and a grammar pattern for translating
+// the sentence, without giving the answer away
+func (c *Client) GenerateHint(ctx context.Context, sentence, difficulty string, direction Direction) (string, error) {
+	text, err := c.generateText(ctx, fmt.Sprintf(promptsFor(direction).hint, sentence, difficulty), 300)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate hint: %w", err)
+	}
+	return text, nil
+}
+
+// GenerateModelAnswer generates a natural translation of the sentence
+func (c *Client) GenerateModelAnswer(ctx context.Context, sentence string, direction Direction) (string, error) {
+	text, err := c.generateText(ctx, fmt.Sprintf(promptsFor(direction).modelAnswer, sentence), 200)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate model answer: %w", err)
+	}
+	return text, nil
+}
 
+// generateText sends a single prompt and returns the text of the reply
+func (c *Client) generateText(ctx context.Context, prompt string, maxTokens int64) (string, error) {
 	message, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
 		Model:     c.model,
-		MaxTokens: 200,
+		MaxTokens: maxTokens,
 		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
+			anthropic.NewUserMessage(anthropic.NewTextBlock(localizePrompt(ctx, prompt))),
 		},
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to generate question: %w", err)
+		return "", err
 	}
 
 	if len(message.Content) == 0 {
@@ -46,77 +73,86 @@ func (c *Client) GenerateQuestion(ctx context.Context, theme, difficulty string)
 
 // EvaluationResult holds the result of answer evaluation
 type EvaluationResult struct {
-	Score       int    `json:"score"`
-	Feedback    string `json:"feedback"`
-	ModelAnswer string `json:"model_answer"`
+	Score       int          `json:"score"`
+	Feedback    string       `json:"feedback"`
+	ModelAnswer string       `json:"model_answer"`
+	Corrections []Correction `json:"corrections"`
+	KeyPhrases  []KeyPhrase  `json:"key_phrases"`
 }
 
-// EvaluateAnswer evaluates the user's English translation
-func (c *Client) EvaluateAnswer(ctx context.Context, japanese, userAnswer string) (*EvaluationResult, error) {
-	prompt := fmt.Sprintf(EvaluateAnswerPrompt, japanese, userAnswer)
-
-	message, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
-		Model:     c.model,
-		MaxTokens: 500,
-		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
-		},
+// EvaluateAnswer evaluates the user's translation of the sentence. The model is
+// forced to answer through the evaluation tool; failures are retried by withRetry.
+func (c *Client) EvaluateAnswer(ctx context.Context, sentence, userAnswer string, direction Direction) (*EvaluationResult, error) {
+	prompt := fmt.Sprintf(promptsFor(direction).evaluate, sentence, userAnswer)
+	return withRetry(func() (*EvaluationResult, error) {
+		return c.requestEvaluation(ctx, prompt)
 	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to evaluate answer: %w", err)
-	}
+}
 
-	if len(message.Content) == 0 {
-		return nil, fmt.Errorf("empty response from Claude")
+// GenerateExercise generates an exercise of the given type. The model is
+// forced to answer through the exercise tool; failures are retried by withRetry.
+func (c *Client) GenerateExercise(ctx context.Context, exerciseType ExerciseType, theme, difficulty string) (*Exercise, error) {
+	spec, err := specFor(exerciseType)
+	if err != nil {
+		return nil, err
 	}
+	prompt := fmt.Sprintf(spec.generate, theme, difficulty)
 
-	// Parse the response
-	result := parseEvaluationResponse(message.Content[0].Text)
-	return result, nil
+	return withRetry(func() (*Exercise, error) {
+		input, err := c.callTool(ctx, prompt, exerciseToolName, exerciseSchema, exerciseRequired)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate exercise: %w", err)
+		}
+		return parseExercise(input, exerciseType)
+	})
 }
 
-// parseEvaluationResponse parses Claude's evaluation response
-func parseEvaluationResponse(response string) *EvaluationResult {
-	result := &EvaluationResult{
-		Score:       70,
-		Feedback:    response,
-		ModelAnswer: "",
+// EvaluateExercise grades the user's answer to an exercise with the grader of its type
+func (c *Client) EvaluateExercise(ctx context.Context, exercise *Exercise, userAnswer string) (*EvaluationResult, error) {
+	spec, err := specFor(exercise.Type)
+	if err != nil {
+		return nil, err
 	}
+	prompt := fmt.Sprintf(spec.evaluate, exerciseDetails(exercise), userAnswer)
+	return withRetry(func() (*EvaluationResult, error) {
+		return c.requestEvaluation(ctx, prompt)
+	})
+}
 
-	// Simple parsing - extract score, model answer, and feedback
-	lines := splitLines(response)
-	for i, line := range lines {
-		if len(line) > 7 && line[:6] == "SCORE:" {
-			fmt.Sscanf(line, "SCORE: %d", &result.Score)
-		} else if len(line) > 14 && line[:13] == "MODEL_ANSWER:" {
-			result.ModelAnswer = line[14:]
-		} else if len(line) > 10 && line[:9] == "FEEDBACK:" {
-			// Collect remaining lines as feedback
-			result.Feedback = line[10:]
-			for j := i + 1; j < len(lines); j++ {
-				result.Feedback += "\n" + lines[j]
-			}
-			break
-		}
+// requestEvaluation sends one evaluation request and parses the tool call
+func (c *Client) requestEvaluation(ctx context.Context, prompt string) (*EvaluationResult, error) {
+	input, err := c.callTool(ctx, prompt, evaluationToolName, evaluationSchema, evaluationRequired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate answer: %w", err)
 	}
-
-	return result
+	return parseEvaluation(input)
 }
 
-// splitLines splits a string into lines
-func splitLines(s string) []string {
-	var lines []string
-	var current string
-	for _, r := range s {
-		if r == '\n' {
-			lines = append(lines, current)
-			current = ""
-		} else {
-			current += string(r)
-		}
+// callTool sends a prompt the model must answer by calling the named tool and
+// returns the tool input
+func (c *Client) callTool(ctx context.Context, prompt, name string, properties map[string]any, required []string) ([]byte, error) {
+	message, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     c.model,
+		MaxTokens: 1024,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(localizePrompt(ctx, prompt))),
+		},
+		Tools: []anthropic.ToolUnionParam{
+			anthropic.ToolUnionParamOfTool(anthropic.ToolInputSchemaParam{
+				Properties: properties,
+				Required:   required,
+			}, name),
+		},
+		ToolChoice: anthropic.ToolChoiceParamOfTool(name),
+	})
+	if err != nil {
+		return nil, err
 	}
-	if current != "" {
-		lines = append(lines, current)
+
+	for _, block := range message.Content {
+		if block.Type == "tool_use" && block.Name == name {
+			return block.Input, nil
+		}
 	}
-	return lines
+	return nil, fmt.Errorf("no %s call in response from Claude", name)
 }
@@ -1,49 +1,94 @@
 package claude
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
 )
 
-func TestParseEvaluationResponse(t *testing.T) {
+func TestParseEvaluation(t *testing.T) {
 	tests := []struct {
-		name           string
-		response       string
-		expectedScore  int
-		expectedModel  string
+		name             string
+		input            string
+		wantErr          bool
+		expectedScore    int
+		expectedModel    string
 		expectedFeedback string
+		expectedCount    int
+		expectedPhrases  int
 	}{
 		{
-			name: "full response",
-			response: `SCORE: 85
-MODEL_ANSWER: This is a test.
-FEEDBACK: Great job! Your answer is correct.`,
-			expectedScore:  85,
-			expectedModel:  "This is a test.",
-			expectedFeedback: "Great job! Your answer is correct.",
+			name:             "full response",
+			input:            `{"score": 85, "model_answer": "This is a test.", "feedback": "よくできました！", "corrections": []}`,
+			expectedScore:    85,
+			expectedModel:    "This is a test.",
+			expectedFeedback: "よくできました！",
+		},
+		{
+			name: "with corrections",
+			input: `{"score": 60, "model_answer": "I went to school.", "feedback": "時制に注意", "corrections": [
+				{"category": "grammar", "span": "go", "suggestion": "went", "explanation": "過去形にします"}
+			]}`,
+			expectedScore:    60,
+			expectedModel:    "I went to school.",
+			expectedFeedback: "時制に注意",
+			expectedCount:    1,
+		},
+		{
+			name: "fine-grained category",
+			input: `{"score": 70, "model_answer": "I saw the movie.", "feedback": "冠詞に注意", "corrections": [
+				{"category": "article", "span": "a movie", "suggestion": "the movie", "explanation": "特定の映画です"}
+			]}`,
+			expectedScore:    70,
+			expectedModel:    "I saw the movie.",
+			expectedFeedback: "冠詞に注意",
+			expectedCount:    1,
+		},
+		{
+			name:             "zero score is valid",
+			input:            `{"score": 0, "model_answer": "Hello.", "feedback": "回答がありません"}`,
+			expectedScore:    0,
+			expectedModel:    "Hello.",
+			expectedFeedback: "回答がありません",
 		},
 		{
-			name: "multiline feedback",
-			response: `SCORE: 70
-MODEL_ANSWER: Hello world.
-FEEDBACK: Good attempt!
-You could improve by using more natural expressions.
-Keep practicing!`,
-			expectedScore:  70,
-			expectedModel:  "Hello world.",
-			expectedFeedback: "Good attempt!\nYou could improve by using more natural expressions.\nKeep practicing!",
+			name: "with key phrases",
+			input: `{"score": 90, "model_answer": "I went to school.", "feedback": "ok", "key_phrases": [
+				{"phrase": " go to school ", "meaning": "学校に行く"}, {"phrase": "", "meaning": "空"}
+			]}`,
+			expectedScore:    90,
+			expectedModel:    "I went to school.",
+			expectedFeedback: "ok",
+			expectedPhrases:  1,
 		},
+		{name: "malformed json", input: `SCORE: 70`, wantErr: true},
+		{name: "missing score", input: `{"model_answer": "Hi.", "feedback": "ok"}`, wantErr: true},
+		{name: "score out of range", input: `{"score": 150, "model_answer": "Hi.", "feedback": "ok"}`, wantErr: true},
+		{name: "missing model answer", input: `{"score": 80, "model_answer": " ", "feedback": "ok"}`, wantErr: true},
+		{name: "missing feedback", input: `{"score": 80, "model_answer": "Hi."}`, wantErr: true},
 		{
-			name: "no structured format",
-			response: "Just some feedback without structure",
-			expectedScore:  70,  // default
-			expectedModel:  "",
-			expectedFeedback: "Just some feedback without structure",
+			name:    "unknown correction category",
+			input:   `{"score": 80, "model_answer": "Hi.", "feedback": "ok", "corrections": [{"category": "style", "span": "a", "suggestion": "b"}]}`,
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := parseEvaluationResponse(tt.response)
+			result, err := parseEvaluation([]byte(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 
 			if result.Score != tt.expectedScore {
 				t.Errorf("Expected score %d, got %d", tt.expectedScore, result.Score)
@@ -51,47 +96,63 @@ Keep practicing!`,
 			if result.ModelAnswer != tt.expectedModel {
 				t.Errorf("Expected model answer '%s', got '%s'", tt.expectedModel, result.ModelAnswer)
 			}
-			// Feedback comparison - just check it's not empty for structured responses
-			if tt.name != "no structured format" && result.Feedback != tt.expectedFeedback {
+			if result.Feedback != tt.expectedFeedback {
 				t.Errorf("Expected feedback '%s', got '%s'", tt.expectedFeedback, result.Feedback)
 			}
+			if len(result.Corrections) != tt.expectedCount {
+				t.Errorf("Expected %d corrections, got %d", tt.expectedCount, len(result.Corrections))
+			}
+			if len(result.KeyPhrases) != tt.expectedPhrases {
+				t.Errorf("Expected %d key phrases, got %+v", tt.expectedPhrases, result.KeyPhrases)
+			}
+			for _, k := range result.KeyPhrases {
+				if k.Phrase != "go to school" {
+					t.Errorf("Expected trimmed key phrase, got %q", k.Phrase)
+				}
+			}
 		})
 	}
 }
 
-func TestSplitLines(t *testing.T) {
+func TestClient_APIErrorRetries(t *testing.T) {
 	tests := []struct {
-		input    string
-		expected []string
+		name      string
+		status    int
+		wantCalls int
 	}{
-		{
-			input:    "line1\nline2\nline3",
-			expected: []string{"line1", "line2", "line3"},
-		},
-		{
-			input:    "single line",
-			expected: []string{"single line"},
-		},
-		{
-			input:    "",
-			expected: []string{},
-		},
-		{
-			input:    "line1\n",
-			expected: []string{"line1"},
-		},
+		{"bad key is not retried", http.StatusUnauthorized, 1},
+		{"overloaded is retried", 529, maxAttempts},
 	}
 
 	for _, tt := range tests {
-		result := splitLines(tt.input)
-		if len(result) != len(tt.expected) {
-			t.Errorf("For input '%s': expected %d lines, got %d", tt.input, len(tt.expected), len(result))
-			continue
-		}
-		for i, line := range result {
-			if line != tt.expected[i] {
-				t.Errorf("For input '%s': expected line[%d] = '%s', got '%s'", tt.input, i, tt.expected[i], line)
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"type": "error", "error": {"type": "api_error", "message": "failed"}}`))
+			}))
+			defer server.Close()
+
+			// Disable the SDK's own retries to count the client's
+			api := anthropic.NewClient(option.WithAPIKey("test-key"), option.WithBaseURL(server.URL), option.WithMaxRetries(0))
+			client := &Client{client: &api, model: "test-model"}
+
+			if _, err := client.GenerateExercise(context.Background(), ExerciseCloze, "旅行", "beginner"); err == nil {
+				t.Error("Expected an error from GenerateExercise")
 			}
-		}
+			if calls != tt.wantCalls {
+				t.Errorf("Expected GenerateExercise to make %d calls, got %d", tt.wantCalls, calls)
+			}
+
+			calls = 0
+			if _, err := client.EvaluateAnswer(context.Background(), "こんにちは", "Hello", JapaneseToEnglish); err == nil {
+				t.Error("Expected an error from EvaluateAnswer")
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Expected EvaluateAnswer to make %d calls, got %d", tt.wantCalls, calls)
+			}
+		})
 	}
 }
@@ -0,0 +1,168 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// evaluationToolName is the tool the model must call to return its evaluation
+const evaluationToolName = "submit_evaluation"
+
+// CorrectionCategories are the allowed values for Correction.Category, the
+// taxonomy errors are tracked by. "grammar" covers grammar errors that fit
+// none of the more specific categories.
+var CorrectionCategories = []string{
+	"article",
+	"tense",
+	"preposition",
+	"word_order",
+	"agreement",
+	"vocabulary",
+	"spelling",
+	"grammar",
+	"naturalness",
+	"other",
+}
+
+// Correction describes a single error in the user's answer
+type Correction struct {
+	Category    string `json:"category"`
+	Span        string `json:"span"`
+	Suggestion  string `json:"suggestion"`
+	Explanation string `json:"explanation"`
+}
+
+// KeyPhrase is a word or phrase from a model answer worth memorising
+type KeyPhrase struct {
+	Phrase  string `json:"phrase"`
+	Meaning string `json:"meaning"`
+}
+
+// evaluationSchema is the JSON schema of the evaluation tool input
+var evaluationSchema = map[string]any{
+	"score": map[string]any{
+		"type":        "integer",
+		"minimum":     0,
+		"maximum":     100,
+		"description": "0-100の評価点",
+	},
+	"model_answer": map[string]any{
+		"type":        "string",
+		"description": "理想的な訳文",
+	},
+	"feedback": map[string]any{
+		"type":        "string",
+		"description": "日本語での詳細なフィードバック",
+	},
+	"corrections": map[string]any{
+		"type":        "array",
+		"description": "ユーザーの回答に含まれる個々の誤り。誤りがなければ空配列",
+		"items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"category": map[string]any{
+					"type":        "string",
+					"enum":        CorrectionCategories,
+					"description": "誤りの分類。article: 冠詞、tense: 時制、preposition: 前置詞、word_order: 語順、agreement: 主語と動詞の一致・単数複数、vocabulary: 語の選択、spelling: つづり、grammar: その他の文法、naturalness: 不自然な表現、other: その他",
+				},
+				"span": map[string]any{
+					"type":        "string",
+					"description": "ユーザーの回答中の誤っている部分（原文のまま）",
+				},
+				"suggestion": map[string]any{
+					"type":        "string",
+					"description": "訂正後の表現",
+				},
+				"explanation": map[string]any{
+					"type":        "string",
+					"description": "日本語での短い説明",
+				},
+			},
+			"required": []string{"category", "span", "suggestion", "explanation"},
+		},
+	},
+	"key_phrases": map[string]any{
+		"type":        "array",
+		"description": "学習者が覚えるべき重要な英語の語句（2〜3個）",
+		"items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"phrase": map[string]any{
+					"type":        "string",
+					"description": "英語の単語・フレーズ",
+				},
+				"meaning": map[string]any{
+					"type":        "string",
+					"description": "日本語の意味",
+				},
+			},
+			"required": []string{"phrase", "meaning"},
+		},
+	},
+}
+
+// evaluationRequired lists the required fields of the evaluation tool input
+var evaluationRequired = []string{"score", "model_answer", "feedback", "corrections"}
+
+// parseEvaluation decodes and validates the evaluation tool input
+func parseEvaluation(input []byte) (*EvaluationResult, error) {
+	var raw struct {
+		Score       *int         `json:"score"`
+		ModelAnswer string       `json:"model_answer"`
+		Feedback    string       `json:"feedback"`
+		Corrections []Correction `json:"corrections"`
+		KeyPhrases  []KeyPhrase  `json:"key_phrases"`
+	}
+	if err := json.Unmarshal(input, &raw); err != nil {
+		return nil, fmt.Errorf("malformed evaluation: %w", err)
+	}
+
+	if raw.Score == nil {
+		return nil, fmt.Errorf("evaluation is missing score")
+	}
+	if *raw.Score < 0 || *raw.Score > 100 {
+		return nil, fmt.Errorf("evaluation score %d out of range", *raw.Score)
+	}
+	if strings.TrimSpace(raw.ModelAnswer) == "" {
+		return nil, fmt.Errorf("evaluation is missing model answer")
+	}
+	if strings.TrimSpace(raw.Feedback) == "" {
+		return nil, fmt.Errorf("evaluation is missing feedback")
+	}
+	for i, c := range raw.Corrections {
+		if !isCorrectionCategory(c.Category) {
+			return nil, fmt.Errorf("correction %d has unknown category %q", i, c.Category)
+		}
+		if strings.TrimSpace(c.Suggestion) == "" {
+			return nil, fmt.Errorf("correction %d is missing suggestion", i)
+		}
+	}
+
+	// Key phrases are optional; incomplete ones are dropped rather than retried
+	var keyPhrases []KeyPhrase
+	for _, k := range raw.KeyPhrases {
+		k.Phrase, k.Meaning = strings.TrimSpace(k.Phrase), strings.TrimSpace(k.Meaning)
+		if k.Phrase != "" {
+			keyPhrases = append(keyPhrases, k)
+		}
+	}
+
+	return &EvaluationResult{
+		Score:       *raw.Score,
+		ModelAnswer: strings.TrimSpace(raw.ModelAnswer),
+		Feedback:    strings.TrimSpace(raw.Feedback),
+		Corrections: raw.Corrections,
+		KeyPhrases:  keyPhrases,
+	}, nil
+}
+
+// isCorrectionCategory reports whether category is one of CorrectionCategories
+func isCorrectionCategory(category string) bool {
+	for _, c := range CorrectionCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ExerciseType is the kind of exercise a question is
+type ExerciseType string
+
+// Exercise types. Translation questions use GenerateQuestion and
+// EvaluateAnswer; the others use GenerateExercise and EvaluateExercise.
+const (
+	ExerciseTranslation ExerciseType = "translation"
+	ExerciseCloze       ExerciseType = "cloze"
+	ExerciseChoice      ExerciseType = "choice"
+	ExerciseReorder     ExerciseType = "reorder"
+	ExerciseCorrection  ExerciseType = "correction"
+
+	// ExerciseVocab asks for a phrase from the learner's vocabulary notebook
+	// given its meaning. It is built from the notebook rather than generated.
+	ExerciseVocab ExerciseType = "vocab"
+)
+
+// ClozeBlank marks the blank in cloze and multiple choice prompts
+const ClozeBlank = "___"
+
+// ReorderSeparator separates the scrambled words of reordering prompts
+const ReorderSeparator = " / "
+
+// Exercise is a generated exercise other than free translation
+type Exercise struct {
+	Type       ExerciseType `json:"-"`
+	Prompt     string       `json:"prompt"`     // shown to the learner
+	Context    string       `json:"context"`    // Japanese meaning of the target sentence, may be empty
+	Choices    []string     `json:"choices"`    // options of multiple choice exercises
+	Answer     string       `json:"answer"`     // canonical answer
+	Alternates []string     `json:"alternates"` // other accepted answers
+}
+
+// exerciseToolName is the tool the model must call to return a generated exercise
+const exerciseToolName = "submit_exercise"
+
+// exerciseSchema is the JSON schema of the exercise tool input
+var exerciseSchema = map[string]any{
+	"prompt": map[string]any{
+		"type":        "string",
+		"description": "学習者に表示する問題文（英語）",
+	},
+	"context": map[string]any{
+		"type":        "string",
+		"description": "完成した英文の日本語訳",
+	},
+	"choices": map[string]any{
+		"type":        "array",
+		"items":       map[string]any{"type": "string"},
+		"description": "多肢選択問題の選択肢。それ以外の問題では空配列",
+	},
+	"answer": map[string]any{
+		"type":        "string",
+		"description": "正解。穴埋め・4択では空欄に入る語句、並べ替え・誤り訂正では完成した英文",
+	},
+	"alternates": map[string]any{
+		"type":        "array",
+		"items":       map[string]any{"type": "string"},
+		"description": "正解として認める別解。なければ空配列",
+	},
+}
+
+// exerciseRequired lists the required fields of the exercise tool input
+var exerciseRequired = []string{"prompt", "context", "choices", "answer", "alternates"}
+
+// exerciseSpec holds the generator and grader prompts of an exercise type
+type exerciseSpec struct {
+	generate string
+	evaluate string
+}
+
+// exerciseSpecs maps each exercise type to its prompts
+var exerciseSpecs = map[ExerciseType]exerciseSpec{
+	ExerciseCloze:      {GenerateClozePrompt, EvaluateClozePrompt},
+	ExerciseChoice:     {GenerateChoicePrompt, EvaluateChoicePrompt},
+	ExerciseReorder:    {GenerateReorderPrompt, EvaluateReorderPrompt},
+	ExerciseCorrection: {GenerateCorrectionPrompt, EvaluateCorrectionPrompt},
+}
+
+// specFor returns the prompts of an exercise type
+func specFor(exerciseType ExerciseType) (exerciseSpec, error) {
+	spec, ok := exerciseSpecs[exerciseType]
+	if !ok {
+		return exerciseSpec{}, fmt.Errorf("unknown exercise type %q", exerciseType)
+	}
+	return spec, nil
+}
+
+// exerciseDetails describes an exercise for the grader prompt
+func exerciseDetails(e *Exercise) string {
+	lines := []string{"問題: " + e.Prompt}
+	if e.Context != "" {
+		lines = append(lines, "日本語訳: "+e.Context)
+	}
+	if len(e.Choices) > 0 {
+		lines = append(lines, "選択肢: "+strings.Join(e.Choices, " / "))
+	}
+	if e.Answer != "" {
+		lines = append(lines, "想定解答: "+e.Answer)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseExercise decodes and validates the exercise tool input
+func parseExercise(input []byte, exerciseType ExerciseType) (*Exercise, error) {
+	e := &Exercise{Type: exerciseType}
+	if err := json.Unmarshal(input, e); err != nil {
+		return nil, fmt.Errorf("malformed exercise: %w", err)
+	}
+
+	e.Prompt = strings.TrimSpace(e.Prompt)
+	e.Context = strings.TrimSpace(e.Context)
+	e.Answer = strings.TrimSpace(e.Answer)
+	if e.Prompt == "" {
+		return nil, fmt.Errorf("exercise is missing prompt")
+	}
+	if e.Answer == "" {
+		return nil, fmt.Errorf("exercise is missing answer")
+	}
+
+	var alternates []string
+	for _, a := range e.Alternates {
+		if a = strings.TrimSpace(a); a != "" && a != e.Answer {
+			alternates = append(alternates, a)
+		}
+	}
+	e.Alternates = alternates
+
+	switch exerciseType {
+	case ExerciseCloze:
+		if !strings.Contains(e.Prompt, ClozeBlank) {
+			return nil, fmt.Errorf("cloze prompt has no blank")
+		}
+		e.Choices = nil
+	case ExerciseChoice:
+		if len(e.Choices) < 2 || len(e.Choices) > 5 {
+			return nil, fmt.Errorf("multiple choice exercise has %d choices", len(e.Choices))
+		}
+		seen := make(map[string]bool)
+		for i, c := range e.Choices {
+			c = strings.TrimSpace(c)
+			if c == "" || seen[c] {
+				return nil, fmt.Errorf("choice %d is empty or repeated", i)
+			}
+			seen[c] = true
+			e.Choices[i] = c
+		}
+		if !seen[e.Answer] {
+			return nil, fmt.Errorf("answer %q is not one of the choices", e.Answer)
+		}
+		// Only the listed choices can be picked
+		e.Alternates = nil
+	case ExerciseReorder:
+		if !strings.Contains(e.Prompt, strings.TrimSpace(ReorderSeparator)) {
+			return nil, fmt.Errorf("reorder prompt has no separated words")
+		}
+		e.Choices = nil
+	default:
+		e.Choices = nil
+	}
+	return e, nil
+}
@@ -0,0 +1,69 @@
+package claude
+
+import "testing"
+
+func TestParseExercise(t *testing.T) {
+	tests := []struct {
+		name         string
+		exerciseType ExerciseType
+		input        string
+		wantErr      bool
+		wantChoices  int
+	}{
+		{
+			name:         "cloze",
+			exerciseType: ExerciseCloze,
+			input:        `{"prompt": "I ___ coffee.", "context": "コーヒーを飲む", "choices": [], "answer": "drink", "alternates": []}`,
+		},
+		{
+			name:         "multiple choice",
+			exerciseType: ExerciseChoice,
+			input:        `{"prompt": "I ___ coffee.", "context": "", "choices": ["drink", " drinks ", "drank"], "answer": "drink"}`,
+			wantChoices:  3,
+		},
+		{
+			name:         "reorder",
+			exerciseType: ExerciseReorder,
+			input:        `{"prompt": "coffee / i / drink", "context": "", "choices": [], "answer": "I drink coffee."}`,
+		},
+		{
+			name:         "correction ignores choices",
+			exerciseType: ExerciseCorrection,
+			input:        `{"prompt": "I drinks coffee.", "context": "", "choices": ["x"], "answer": "I drink coffee."}`,
+		},
+		{name: "malformed json", exerciseType: ExerciseCloze, input: `blank`, wantErr: true},
+		{name: "missing prompt", exerciseType: ExerciseCorrection, input: `{"prompt": " "}`, wantErr: true},
+		{name: "missing answer", exerciseType: ExerciseCloze, input: `{"prompt": "I ___ coffee.", "answer": " "}`, wantErr: true},
+		{name: "answer not a choice", exerciseType: ExerciseChoice, input: `{"prompt": "I ___.", "choices": ["a", "b"], "answer": "c"}`, wantErr: true},
+		{name: "cloze without blank", exerciseType: ExerciseCloze, input: `{"prompt": "I drink coffee.", "answer": "drink"}`, wantErr: true},
+		{name: "too few choices", exerciseType: ExerciseChoice, input: `{"prompt": "I ___.", "choices": ["a"], "answer": "a"}`, wantErr: true},
+		{name: "repeated choices", exerciseType: ExerciseChoice, input: `{"prompt": "I ___.", "choices": ["a", "a"], "answer": "a"}`, wantErr: true},
+		{name: "reorder without separators", exerciseType: ExerciseReorder, input: `{"prompt": "i drink coffee", "answer": "I drink coffee."}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exercise, err := parseExercise([]byte(tt.input), tt.exerciseType)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", exercise)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if exercise.Type != tt.exerciseType {
+				t.Errorf("Expected type %s, got %s", tt.exerciseType, exercise.Type)
+			}
+			if len(exercise.Choices) != tt.wantChoices {
+				t.Errorf("Expected %d choices, got %v", tt.wantChoices, exercise.Choices)
+			}
+			for _, c := range exercise.Choices {
+				if c != "drink" && c != "drinks" && c != "drank" {
+					t.Errorf("Expected trimmed choices, got %q", c)
+				}
+			}
+		})
+	}
+}
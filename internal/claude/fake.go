@@ -0,0 +1,201 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// fakeQuestion pairs a question with the answer the fake treats as correct
+// and the key phrase it picks from it
+type fakeQuestion struct {
+	japanese  string
+	english   string
+	keyPhrase KeyPhrase
+}
+
+var fakeQuestions = []fakeQuestion{
+	{"私は毎朝コーヒーを飲みます。", "I drink coffee every morning.", KeyPhrase{"every morning", "毎朝"}},
+	{"駅までの道を教えてください。", "Please tell me the way to the station.", KeyPhrase{"the way to", "〜への道"}},
+	{"昨日は雨が降っていました。", "It was raining yesterday.", KeyPhrase{"it was raining", "雨が降っていた"}},
+	{"この本はとても面白いです。", "This book is very interesting.", KeyPhrase{"interesting", "面白い"}},
+}
+
+// Fake is a deterministic, offline QuizModel for tests and local development.
+// Questions are served in a fixed rotation and answers are scored by word
+// overlap with a known model answer.
+type Fake struct {
+	mu   sync.Mutex
+	next int
+}
+
+// NewFake creates a new fake model
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+// GenerateQuestion returns the next question in the rotation, in Japanese or
+// English depending on the direction. The focus is ignored.
+func (f *Fake) GenerateQuestion(ctx context.Context, theme, difficulty string, direction Direction, focus Focus) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q := fakeQuestions[f.next%len(fakeQuestions)]
+	f.next++
+	if direction == EnglishToJapanese {
+		return q.english, nil
+	}
+	return q.japanese, nil
+}
+
+// GenerateHint reveals the first word, or for Japanese the first character, of
+// the known model answer
+func (f *Fake) GenerateHint(ctx context.Context, sentence, difficulty string, direction Direction) (string, error) {
+	answer := fakeModelAnswer(sentence, direction)
+	first := strings.Fields(answer)[0]
+	if direction == EnglishToJapanese {
+		first = string([]rune(answer)[:1])
+	}
+	return fmt.Sprintf("「%s」で始めてみましょう。", first), nil
+}
+
+// GenerateModelAnswer returns the known model answer
+func (f *Fake) GenerateModelAnswer(ctx context.Context, sentence string, direction Direction) (string, error) {
+	return fakeModelAnswer(sentence, direction), nil
+}
+
+// EvaluateAnswer scores the answer by the share of model answer words it
+// contains. Japanese answers are compared character by character.
+func (f *Fake) EvaluateAnswer(ctx context.Context, sentence, userAnswer string, direction Direction) (*EvaluationResult, error) {
+	modelAnswer := fakeModelAnswer(sentence, direction)
+
+	expected := fakeWords(modelAnswer, direction)
+	given := make(map[string]bool)
+	for _, w := range fakeWords(userAnswer, direction) {
+		given[w] = true
+	}
+
+	matched := 0
+	for _, w := range expected {
+		if given[w] {
+			matched++
+		}
+	}
+	score := matched * 100 / len(expected)
+
+	result := &EvaluationResult{
+		Score:       score,
+		ModelAnswer: modelAnswer,
+		Feedback:    fmt.Sprintf("模範解答の単語を %d/%d 個使えています。", matched, len(expected)),
+	}
+	for _, q := range fakeQuestions {
+		if q.japanese == sentence || q.english == sentence {
+			result.KeyPhrases = []KeyPhrase{q.keyPhrase}
+		}
+	}
+	return result, nil
+}
+
+// fakeModelAnswer returns the answer the fake treats as correct for a question
+func fakeModelAnswer(sentence string, direction Direction) string {
+	for _, q := range fakeQuestions {
+		if direction == EnglishToJapanese && q.english == sentence {
+			return q.japanese
+		}
+		if direction != EnglishToJapanese && q.japanese == sentence {
+			return q.english
+		}
+	}
+	if direction == EnglishToJapanese {
+		return "この文は知りません。"
+	}
+	return "I don't know this sentence."
+}
+
+// fakeWords splits s into the units answers are compared by: lowercase words
+// without punctuation for English, characters without punctuation for Japanese
+func fakeWords(s string, direction Direction) []string {
+	if direction == EnglishToJapanese {
+		var chars []string
+		for _, r := range s {
+			if !strings.ContainsRune("。、！？「」 ", r) {
+				chars = append(chars, string(r))
+			}
+		}
+		return chars
+	}
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r == '\'')
+	})
+}
+
+// GenerateExercise builds an exercise from the next question in the rotation:
+// the second word is blanked for cloze and multiple choice, the words are
+// reversed for reordering and the second word is misspelt for correction
+func (f *Fake) GenerateExercise(ctx context.Context, exerciseType ExerciseType, theme, difficulty string) (*Exercise, error) {
+	if _, err := specFor(exerciseType); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	q := fakeQuestions[f.next%len(fakeQuestions)]
+	f.next++
+	f.mu.Unlock()
+
+	words := strings.Fields(strings.TrimSuffix(q.english, "."))
+	e := &Exercise{Type: exerciseType, Context: q.japanese}
+	switch exerciseType {
+	case ExerciseCloze, ExerciseChoice:
+		blanked := append([]string{}, words...)
+		blanked[1] = ClozeBlank
+		e.Prompt = strings.Join(blanked, " ") + "."
+		e.Answer = words[1]
+		if exerciseType == ExerciseChoice {
+			for _, other := range fakeQuestions {
+				e.Choices = append(e.Choices, strings.Fields(other.english)[1])
+			}
+		}
+	case ExerciseReorder:
+		reversed := make([]string, len(words))
+		for i, w := range words {
+			reversed[len(words)-1-i] = strings.ToLower(w)
+		}
+		e.Prompt = strings.Join(reversed, ReorderSeparator)
+		e.Answer = q.english
+	case ExerciseCorrection:
+		wrong := append([]string{}, words...)
+		wrong[1] += "s"
+		e.Prompt = strings.Join(wrong, " ") + "."
+		e.Answer = q.english
+	}
+	return e, nil
+}
+
+// EvaluateExercise scores the answer by word overlap with the expected answer:
+// the blanked word for cloze and multiple choice, the original sentence otherwise
+func (f *Fake) EvaluateExercise(ctx context.Context, exercise *Exercise, userAnswer string) (*EvaluationResult, error) {
+	modelAnswer := fakeModelAnswer(exercise.Context, JapaneseToEnglish)
+	expected := fakeWords(modelAnswer, JapaneseToEnglish)
+	if exercise.Type == ExerciseCloze || exercise.Type == ExerciseChoice {
+		expected = expected[1:2]
+	}
+
+	given := make(map[string]bool)
+	for _, w := range fakeWords(userAnswer, JapaneseToEnglish) {
+		given[w] = true
+	}
+
+	matched := 0
+	for _, w := range expected {
+		if given[w] {
+			matched++
+		}
+	}
+
+	return &EvaluationResult{
+		Score:       matched * 100 / len(expected),
+		ModelAnswer: modelAnswer,
+		Feedback:    fmt.Sprintf("正解の単語を %d/%d 個使えています。", matched, len(expected)),
+	}, nil
+}
@@ -0,0 +1,107 @@
+package claude
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+
+	first, _ := fake.GenerateQuestion(ctx, "旅行", "beginner", JapaneseToEnglish, Focus{})
+	second, _ := fake.GenerateQuestion(ctx, "旅行", "beginner", JapaneseToEnglish, Focus{})
+	if first == second {
+		t.Error("Expected consecutive questions to differ")
+	}
+	if again, _ := NewFake().GenerateQuestion(ctx, "旅行", "beginner", JapaneseToEnglish, Focus{}); again != first {
+		t.Errorf("Expected deterministic questions, got '%s' and '%s'", first, again)
+	}
+
+	tests := []struct {
+		answer   string
+		expected int
+	}{
+		{"I drink coffee every morning.", 100},
+		{"i drink coffee", 60},
+		{"Something else entirely", 0},
+	}
+	for _, tt := range tests {
+		result, err := fake.EvaluateAnswer(ctx, "私は毎朝コーヒーを飲みます。", tt.answer, JapaneseToEnglish)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Score != tt.expected {
+			t.Errorf("For answer '%s': expected score %d, got %d", tt.answer, tt.expected, result.Score)
+		}
+	}
+}
+
+func TestFake_HintAndModelAnswer(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+
+	hint, err := fake.GenerateHint(ctx, "私は毎朝コーヒーを飲みます。", "beginner", JapaneseToEnglish)
+	if err != nil || hint != "「I」で始めてみましょう。" {
+		t.Errorf("Unexpected hint %q (%v)", hint, err)
+	}
+
+	answer, err := fake.GenerateModelAnswer(ctx, "私は毎朝コーヒーを飲みます。", JapaneseToEnglish)
+	if err != nil || answer != "I drink coffee every morning." {
+		t.Errorf("Unexpected model answer %q (%v)", answer, err)
+	}
+}
+
+func TestFake_EnglishToJapanese(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+
+	question, _ := fake.GenerateQuestion(ctx, "旅行", "beginner", EnglishToJapanese, Focus{})
+	if question != "I drink coffee every morning." {
+		t.Fatalf("Expected an English question, got %q", question)
+	}
+
+	result, err := fake.EvaluateAnswer(ctx, question, "私は毎朝コーヒーを飲みます。", EnglishToJapanese)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Score != 100 || result.ModelAnswer != "私は毎朝コーヒーを飲みます。" {
+		t.Errorf("Expected a perfect Japanese answer, got %+v", result)
+	}
+}
+
+func TestFake_Exercises(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		exerciseType ExerciseType
+		prompt       string
+		answer       string
+	}{
+		{ExerciseCloze, "I ___ coffee every morning.", "drink"},
+		{ExerciseChoice, "I ___ coffee every morning.", "drink"},
+		{ExerciseReorder, "morning / every / coffee / drink / i", "I drink coffee every morning."},
+		{ExerciseCorrection, "I drinks coffee every morning.", "I drink coffee every morning."},
+	}
+	for _, tt := range tests {
+		exercise, err := NewFake().GenerateExercise(ctx, tt.exerciseType, "旅行", "beginner")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if exercise.Prompt != tt.prompt {
+			t.Errorf("%s: expected prompt %q, got %q", tt.exerciseType, tt.prompt, exercise.Prompt)
+		}
+		if tt.exerciseType == ExerciseChoice && len(exercise.Choices) != len(fakeQuestions) {
+			t.Errorf("Expected %d choices, got %v", len(fakeQuestions), exercise.Choices)
+		}
+
+		result, _ := NewFake().EvaluateExercise(ctx, exercise, tt.answer)
+		if result.Score != 100 {
+			t.Errorf("%s: expected full score for %q, got %d", tt.exerciseType, tt.answer, result.Score)
+		}
+	}
+
+	if _, err := NewFake().GenerateExercise(ctx, ExerciseTranslation, "旅行", "beginner"); err == nil {
+		t.Error("Expected error for translation exercise")
+	}
+}
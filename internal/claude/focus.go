@@ -0,0 +1,62 @@
+package claude
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Focus narrows a generated question to a grammar point, phrases from the
+// learner's vocabulary notebook or the correction categories they get wrong
+// most often. The zero Focus leaves the question free within its theme.
+type Focus struct {
+	Grammar    string
+	Vocabulary []string
+	Weaknesses []string
+}
+
+// focusDrills describes what a question targeting each correction category
+// should make the learner practise
+var focusDrills = map[string]string{
+	"article":     "冠詞（a / an / the / 無冠詞）の使い分け",
+	"tense":       "時制（過去・現在完了・進行形など）の使い分け",
+	"preposition": "前置詞の選択",
+	"word_order":  "語順（疑問文、副詞の位置、間接疑問など）",
+	"agreement":   "主語と動詞の一致、名詞の単数・複数",
+	"vocabulary":  "似た意味の語の使い分け、コロケーション",
+	"spelling":    "つづりを間違えやすい語",
+	"grammar":     "基本的な文法事項",
+	"naturalness": "直訳では不自然になる、英語らしい言い回し",
+}
+
+// IsZero reports whether the focus leaves the question unconstrained
+func (f Focus) IsZero() bool {
+	return f.Grammar == "" && len(f.Vocabulary) == 0 && len(f.Weaknesses) == 0
+}
+
+// prompt renders the focus as a section of the question generation prompt,
+// or returns an empty string for the zero Focus
+func (f Focus) prompt() string {
+	if f.IsZero() {
+		return ""
+	}
+
+	lines := []string{"重点（必ず問題文に反映してください）:"}
+	if f.Grammar != "" {
+		lines = append(lines, fmt.Sprintf("- 次の文法事項を使う文にしてください: %s", f.Grammar))
+	}
+	if len(f.Vocabulary) > 0 {
+		lines = append(lines, fmt.Sprintf("- 訳すときに次の語句のうち少なくとも1つを使うことになる文にしてください: %s", strings.Join(f.Vocabulary, ", ")))
+	}
+	if len(f.Weaknesses) > 0 {
+		var drills []string
+		for _, category := range f.Weaknesses {
+			if drill, ok := focusDrills[category]; ok {
+				drills = append(drills, drill)
+			}
+		}
+		if len(drills) > 0 {
+			lines = append(lines, fmt.Sprintf("- 学習者がよく間違える次の点を正しく使わないと訳せない文にしてください: %s", strings.Join(drills, "、")))
+		}
+	}
+	return strings.Join(lines, "\n") + "\n\n"
+}
@@ -0,0 +1,27 @@
+package claude
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFocusPrompt(t *testing.T) {
+	if got := (Focus{}).prompt(); got != "" {
+		t.Errorf("Expected no focus section for the zero Focus, got %q", got)
+	}
+
+	prompt := Focus{
+		Grammar:    "present perfect",
+		Vocabulary: []string{"every morning", "the way to"},
+		Weaknesses: []string{"article", "other", "tense"},
+	}.prompt()
+
+	for _, want := range []string{"present perfect", "every morning, the way to", "冠詞（a / an / the / 無冠詞）の使い分け、時制"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the focus section to contain %q, got %q", want, prompt)
+		}
+	}
+	if !strings.HasSuffix(prompt, "\n\n") {
+		t.Errorf("Expected the focus section to end with a blank line, got %q", prompt)
+	}
+}
@@ -0,0 +1,66 @@
+package claude
+
+import (
+	"fmt"
+	"strings"
+)
+
+// answerReplacer folds typographic quotes to ASCII and turns punctuation that
+// does not change the answer into spaces
+var answerReplacer = strings.NewReplacer(
+	"’", "'", "‘", "'", "“", "\"", "”", "\"",
+	".", " ", ",", " ", "!", " ", "?", " ", ";", " ", ":", " ", "\"", " ",
+)
+
+// normalizeAnswer folds case, punctuation and spacing so that answers differing
+// only in those compare equal
+func normalizeAnswer(s string) string {
+	return strings.Join(strings.Fields(answerReplacer.Replace(strings.ToLower(s))), " ")
+}
+
+// GradeExercise grades an answer to a cloze, multiple choice, reordering or
+// vocabulary exercise against its stored answer and alternates without calling the
+// model. Cloze answers may be the missing word or the completed sentence.
+// It returns false for free text exercises and for exercises saved without an
+// answer, which must be graded by the model.
+func GradeExercise(e *Exercise, userAnswer string) (*EvaluationResult, bool) {
+	switch {
+	case e.Answer == "":
+		return nil, false
+	case e.Type != ExerciseCloze && e.Type != ExerciseChoice && e.Type != ExerciseReorder && e.Type != ExerciseVocab:
+		return nil, false
+	}
+
+	accepted := append([]string{e.Answer}, e.Alternates...)
+	given := normalizeAnswer(userAnswer)
+
+	correct := false
+	for _, a := range accepted {
+		candidates := []string{a}
+		if e.Type == ExerciseCloze {
+			candidates = append(candidates, strings.Replace(e.Prompt, ClozeBlank, a, 1))
+		}
+		for _, c := range candidates {
+			if normalizeAnswer(c) == given {
+				correct = true
+			}
+		}
+	}
+
+	modelAnswer := e.Answer
+	if e.Type == ExerciseCloze || e.Type == ExerciseChoice {
+		modelAnswer = strings.Replace(e.Prompt, ClozeBlank, e.Answer, 1)
+	}
+
+	result := &EvaluationResult{ModelAnswer: modelAnswer}
+	if correct {
+		result.Score = 100
+		result.Feedback = "⭕ 正解です！"
+	} else {
+		result.Feedback = fmt.Sprintf("❌ 不正解です。正解は「%s」です。", e.Answer)
+	}
+	if len(e.Alternates) > 0 {
+		result.Feedback += fmt.Sprintf("\n「%s」も正解です。", strings.Join(e.Alternates, "」「"))
+	}
+	return result, true
+}
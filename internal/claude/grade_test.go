@@ -0,0 +1,54 @@
+package claude
+
+import "testing"
+
+func TestGradeExercise(t *testing.T) {
+	cloze := &Exercise{Type: ExerciseCloze, Prompt: "I ___ coffee every morning.", Answer: "drink", Alternates: []string{"have"}}
+	choice := &Exercise{Type: ExerciseChoice, Prompt: "I ___ coffee.", Choices: []string{"drink", "drinks"}, Answer: "drink"}
+	reorder := &Exercise{Type: ExerciseReorder, Prompt: "coffee / don't / i / drink", Answer: "I don't drink coffee."}
+	vocab := &Exercise{Type: ExerciseVocab, Prompt: "毎朝", Answer: "every morning"}
+
+	tests := []struct {
+		name      string
+		exercise  *Exercise
+		answer    string
+		wantScore int
+	}{
+		{"cloze word", cloze, "drink", 100},
+		{"cloze case and spacing", cloze, "  Drink ", 100},
+		{"cloze alternate", cloze, "have", 100},
+		{"cloze completed sentence", cloze, "I have coffee every morning", 100},
+		{"cloze wrong", cloze, "drinks", 0},
+		{"choice", choice, "drink", 100},
+		{"choice wrong", choice, "drinks", 0},
+		{"reorder", reorder, "i don’t drink coffee", 100},
+		{"reorder wrong order", reorder, "I drink don't coffee.", 0},
+		{"vocab", vocab, "Every morning", 100},
+		{"vocab wrong", vocab, "each morning", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := GradeExercise(tt.exercise, tt.answer)
+			if !ok {
+				t.Fatal("Expected the exercise to be graded locally")
+			}
+			if result.Score != tt.wantScore {
+				t.Errorf("Expected score %d for %q, got %d", tt.wantScore, tt.answer, result.Score)
+			}
+		})
+	}
+
+	if result, _ := GradeExercise(cloze, "drink"); result.ModelAnswer != "I drink coffee every morning." {
+		t.Errorf("Expected the completed sentence as model answer, got %q", result.ModelAnswer)
+	}
+
+	for _, e := range []*Exercise{
+		{Type: ExerciseCorrection, Prompt: "I drinks coffee.", Answer: "I drink coffee."},
+		{Type: ExerciseCloze, Prompt: "I ___ coffee."},
+	} {
+		if _, ok := GradeExercise(e, "drink"); ok {
+			t.Errorf("Expected %s exercise %+v to be left to the model", e.Type, e)
+		}
+	}
+}
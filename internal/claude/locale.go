@@ -0,0 +1,28 @@
+package claude
+
+import "context"
+
+// Locales choose the language the model writes feedback, explanations and
+// hints in. The prompts ask for Japanese unless told otherwise.
+const (
+	LocaleJapanese = "ja"
+	LocaleEnglish  = "en"
+)
+
+// localeKey is the context key of the locale
+type localeKey struct{}
+
+// WithLocale returns a context asking the model to write feedback,
+// explanations and hints in the language of the locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// localizePrompt adds an instruction to write in the language of the
+// context's locale. Sentences to translate and translations keep their language.
+func localizePrompt(ctx context.Context, prompt string) string {
+	if locale, _ := ctx.Value(localeKey{}).(string); locale == LocaleEnglish {
+		return prompt + "\n\nフィードバック、説明、ヒントは日本語ではなく英語で書いてください。問題文、英訳、和訳、模範解答そのものの言語は変えないでください。"
+	}
+	return prompt
+}
@@ -0,0 +1,20 @@
+package claude
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLocalizePrompt(t *testing.T) {
+	ctx := context.Background()
+	if got := localizePrompt(ctx, "prompt"); got != "prompt" {
+		t.Errorf("Expected the prompt unchanged without a locale, got %q", got)
+	}
+	if got := localizePrompt(WithLocale(ctx, LocaleJapanese), "prompt"); got != "prompt" {
+		t.Errorf("Expected the prompt unchanged for Japanese, got %q", got)
+	}
+	if got := localizePrompt(WithLocale(ctx, LocaleEnglish), "prompt"); !strings.HasPrefix(got, "prompt\n\n") || !strings.Contains(got, "英語で書いてください") {
+		t.Errorf("Expected an English instruction appended, got %q", got)
+	}
+}
@@ -0,0 +1,33 @@
+package claude
+
+import "context"
+
+// Direction is the translation direction of a question
+type Direction string
+
+// Translation directions
+const (
+	JapaneseToEnglish Direction = "ja-en"
+	EnglishToJapanese Direction = "en-ja"
+)
+
+// QuizModel generates translation questions, hints, model answers and other
+// exercises, and evaluates answers to them. The sentence passed to the
+// translation methods is the one shown to the learner: Japanese for
+// JapaneseToEnglish questions, English for EnglishToJapanese ones. It is
+// implemented by the Claude client, the OpenAI-compatible client and the
+// in-memory fake.
+type QuizModel interface {
+	GenerateQuestion(ctx context.Context, theme, difficulty string, direction Direction, focus Focus) (string, error)
+	GenerateHint(ctx context.Context, sentence, difficulty string, direction Direction) (string, error)
+	GenerateModelAnswer(ctx context.Context, sentence string, direction Direction) (string, error)
+	EvaluateAnswer(ctx context.Context, sentence, userAnswer string, direction Direction) (*EvaluationResult, error)
+	GenerateExercise(ctx context.Context, exerciseType ExerciseType, theme, difficulty string) (*Exercise, error)
+	EvaluateExercise(ctx context.Context, exercise *Exercise, userAnswer string) (*EvaluationResult, error)
+}
+
+var (
+	_ QuizModel = (*Client)(nil)
+	_ QuizModel = (*OpenAIClient)(nil)
+	_ QuizModel = (*Fake)(nil)
+)
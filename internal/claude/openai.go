@@ -0,0 +1,225 @@
+package claude
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIClient is a QuizModel backed by an OpenAI-compatible chat completions API
+type OpenAIClient struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	model      string
+}
+
+// NewOpenAIClient creates a client for an OpenAI-compatible API.
+// baseURL is the API root, e.g. https://api.openai.com/v1.
+func NewOpenAIClient(baseURL, apiKey, model string) *OpenAIClient {
+	return &OpenAIClient{
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+	}
+}
+
+type openAIMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []openAIToolCall `json:"tool_calls,omitempty"`
+}
+
+type openAIToolCall struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIRequest struct {
+	Model      string          `json:"model"`
+	Messages   []openAIMessage `json:"messages"`
+	MaxTokens  int             `json:"max_tokens,omitempty"`
+	Tools      []any           `json:"tools,omitempty"`
+	ToolChoice any             `json:"tool_choice,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// GenerateQuestion generates a sentence for translation practice in the given
+// direction, targeting the focus if one is given
+func (c *OpenAIClient) GenerateQuestion(ctx context.Context, theme, difficulty string, direction Direction, focus Focus) (string, error) {
+	text, err := c.generateText(ctx, fmt.Sprintf(promptsFor(direction).question, theme, difficulty, focus.prompt()), 200)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate question: %w", err)
+	}
+	return text, nil
+}
+
+// GenerateHint generates key vocabulary and a grammar pattern for translating
+// the sentence, without giving the answer away
+func (c *OpenAIClient) GenerateHint(ctx context.Context, sentence, difficulty string, direction Direction) (string, error) {
+	text, err := c.generateText(ctx, fmt.Sprintf(promptsFor(direction).hint, sentence, difficulty), 300)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate hint: %w", err)
+	}
+	return text, nil
+}
+
+// GenerateModelAnswer generates a natural translation of the sentence
+func (c *OpenAIClient) GenerateModelAnswer(ctx context.Context, sentence string, direction Direction) (string, error) {
+	text, err := c.generateText(ctx, fmt.Sprintf(promptsFor(direction).modelAnswer, sentence), 200)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate model answer: %w", err)
+	}
+	return text, nil
+}
+
+// generateText sends a single prompt and returns the text of the reply
+func (c *OpenAIClient) generateText(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	message, err := c.complete(ctx, openAIRequest{
+		Model:     c.model,
+		MaxTokens: maxTokens,
+		Messages:  []openAIMessage{{Role: "user", Content: localizePrompt(ctx, prompt)}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if message.Content == "" {
+		return "", fmt.Errorf("empty response from model")
+	}
+	return message.Content, nil
+}
+
+// EvaluateAnswer evaluates the user's translation of the sentence through a forced
+// function call; failures are retried by withRetry
+func (c *OpenAIClient) EvaluateAnswer(ctx context.Context, sentence, userAnswer string, direction Direction) (*EvaluationResult, error) {
+	prompt := fmt.Sprintf(promptsFor(direction).evaluate, sentence, userAnswer)
+	return withRetry(func() (*EvaluationResult, error) {
+		return c.requestEvaluation(ctx, prompt)
+	})
+}
+
+// GenerateExercise generates an exercise of the given type through a forced
+// function call; failures are retried by withRetry
+func (c *OpenAIClient) GenerateExercise(ctx context.Context, exerciseType ExerciseType, theme, difficulty string) (*Exercise, error) {
+	spec, err := specFor(exerciseType)
+	if err != nil {
+		return nil, err
+	}
+	prompt := fmt.Sprintf(spec.generate, theme, difficulty)
+
+	return withRetry(func() (*Exercise, error) {
+		args, err := c.callFunction(ctx, prompt, exerciseToolName, exerciseSchema, exerciseRequired)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate exercise: %w", err)
+		}
+		return parseExercise(args, exerciseType)
+	})
+}
+
+// EvaluateExercise grades the user's answer to an exercise with the grader of its type
+func (c *OpenAIClient) EvaluateExercise(ctx context.Context, exercise *Exercise, userAnswer string) (*EvaluationResult, error) {
+	spec, err := specFor(exercise.Type)
+	if err != nil {
+		return nil, err
+	}
+	prompt := fmt.Sprintf(spec.evaluate, exerciseDetails(exercise), userAnswer)
+	return withRetry(func() (*EvaluationResult, error) {
+		return c.requestEvaluation(ctx, prompt)
+	})
+}
+
+// requestEvaluation sends one evaluation request and parses the function call
+func (c *OpenAIClient) requestEvaluation(ctx context.Context, prompt string) (*EvaluationResult, error) {
+	args, err := c.callFunction(ctx, prompt, evaluationToolName, evaluationSchema, evaluationRequired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate answer: %w", err)
+	}
+	return parseEvaluation(args)
+}
+
+// callFunction sends a prompt the model must answer by calling the named
+// function and returns the call's arguments
+func (c *OpenAIClient) callFunction(ctx context.Context, prompt, name string, properties map[string]any, required []string) ([]byte, error) {
+	message, err := c.complete(ctx, openAIRequest{
+		Model:     c.model,
+		MaxTokens: 1024,
+		Messages:  []openAIMessage{{Role: "user", Content: localizePrompt(ctx, prompt)}},
+		Tools: []any{map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name": name,
+				"parameters": map[string]any{
+					"type":       "object",
+					"properties": properties,
+					"required":   required,
+				},
+			},
+		}},
+		ToolChoice: map[string]any{
+			"type":     "function",
+			"function": map[string]any{"name": name},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, call := range message.ToolCalls {
+		if call.Function.Name == name {
+			return []byte(call.Function.Arguments), nil
+		}
+	}
+	return nil, fmt.Errorf("no %s call in response from model", name)
+}
+
+// complete sends a chat completion request and returns the first choice
+func (c *OpenAIClient) complete(ctx context.Context, body openAIRequest) (*openAIMessage, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &statusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: strings.TrimSpace(string(msg))}
+	}
+
+	var result openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("malformed API response: %w", err)
+	}
+	if len(result.Choices) == 0 {
+		return nil, fmt.Errorf("empty response from model")
+	}
+	return &result.Choices[0].Message, nil
+}
@@ -0,0 +1,141 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIClient_EvaluateAnswer(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("Missing authorization header")
+		}
+
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Model != "test-model" {
+			t.Errorf("Expected model 'test-model', got '%s'", req.Model)
+		}
+
+		// First reply is malformed to exercise the retry
+		args := `{"score": "high"}`
+		if calls > 1 {
+			args = `{"score": 90, "model_answer": "Hello.", "feedback": "いいですね", "corrections": []}`
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{
+				"message": map[string]any{
+					"role": "assistant",
+					"tool_calls": []any{map[string]any{
+						"type":     "function",
+						"function": map[string]any{"name": evaluationToolName, "arguments": args},
+					}},
+				},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL+"/", "test-key", "test-model")
+	result, err := client.EvaluateAnswer(context.Background(), "こんにちは", "Hello", JapaneseToEnglish)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+	if result.Score != 90 || result.ModelAnswer != "Hello." {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestOpenAIClient_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "", "test-model")
+	if _, err := client.GenerateQuestion(context.Background(), "旅行", "beginner", JapaneseToEnglish, Focus{}); err == nil {
+		t.Error("Expected error for non-200 response")
+	}
+}
+
+func TestOpenAIClient_GenerateHint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if len(req.Messages) != 1 || !strings.Contains(req.Messages[0].Content, "こんにちは") {
+			t.Errorf("Expected the sentence in the prompt, got %+v", req.Messages)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{
+				"message": map[string]any{"role": "assistant", "content": "- hello"},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "", "test-model")
+	hint, err := client.GenerateHint(context.Background(), "こんにちは", "beginner", JapaneseToEnglish)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if hint != "- hello" {
+		t.Errorf("Expected hint '- hello', got %q", hint)
+	}
+}
+
+func TestOpenAIClient_APIErrorRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantCalls int
+	}{
+		{"bad request is not retried", http.StatusBadRequest, 1},
+		{"unauthorized is not retried", http.StatusUnauthorized, 1},
+		{"rate limit is retried", http.StatusTooManyRequests, maxAttempts},
+		{"server error is retried", http.StatusServiceUnavailable, maxAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				http.Error(w, "failed", tt.status)
+			}))
+			defer server.Close()
+			client := NewOpenAIClient(server.URL, "", "test-model")
+
+			// Exercise generation and answer evaluation share one retry policy
+			if _, err := client.GenerateExercise(context.Background(), ExerciseCloze, "旅行", "beginner"); err == nil {
+				t.Error("Expected an error from GenerateExercise")
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Expected GenerateExercise to make %d calls, got %d", tt.wantCalls, calls)
+			}
+
+			calls = 0
+			if _, err := client.EvaluateAnswer(context.Background(), "こんにちは", "Hello", JapaneseToEnglish); err == nil {
+				t.Error("Expected an error from EvaluateAnswer")
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Expected EvaluateAnswer to make %d calls, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
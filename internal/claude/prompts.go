@@ -6,25 +6,238 @@ const GenerateQuestionPrompt = `あなたは英語学習アシスタントです
 テーマ: %s
 難易度: %s
 
-ルール:
+%sルール:
 - 日本語の文のみを出力してください（それ以外は何も出力しないでください）
 - 自然でよく使われる表現にしてください
 - 難易度に合わせてください
   - beginner（初級）: シンプルな文法、基本的な語彙
   - intermediate（中級）: 複文、一般的な表現
   - advanced（上級）: 複雑な文法、慣用句、ニュアンスのある表現
+  - A1〜C2 の場合は CEFR のその段階の学習者が使いこなせるべき文法と語彙にしてください
 
 日本語の文を出力してください:`
 
+// GenerateHintPrompt is the prompt template for generating hints
+const GenerateHintPrompt = `あなたは英語学習アシスタントです。次の日本語の文を英訳しようとしている学習者にヒントを出してください。
+
+日本語の文: %s
+難易度: %s
+
+ルール:
+- 英訳の答えそのものは絶対に書かないでください
+- 鍵となる英単語・フレーズを2〜3個と、使うべき文法パターンを1つ示してください
+- 日本語で簡潔に、箇条書きで出力してください（それ以外は何も出力しないでください）
+
+ヒントを出力してください:`
+
+// GenerateModelAnswerPrompt is the prompt template for generating model answers
+const GenerateModelAnswerPrompt = `あなたは英語学習アシスタントです。次の日本語の文の自然な英訳を1つ示してください。
+
+日本語の文: %s
+
+英訳のみを出力してください（それ以外は何も出力しないでください）:`
+
 // EvaluateAnswerPrompt is the prompt template for evaluating answers
 const EvaluateAnswerPrompt = `あなたは英語学習アシスタントです。ユーザーの英訳を評価してください。
 
 日本語の文: %s
 ユーザーの回答: %s
 
-以下の形式で評価してください:
-SCORE: [0-100の数値]
-MODEL_ANSWER: [あなたの理想的な英訳]
-FEEDBACK: [日本語での詳細なフィードバック。文法の訂正、語彙の提案、コメントを含めてください]
+submit_evaluation ツールを使って評価結果を返してください:
+- score: 0-100の数値
+- model_answer: あなたの理想的な英訳
+- feedback: 日本語での詳細なフィードバック。文法の訂正、語彙の提案、コメントを含めてください
+- corrections: 回答中の個々の誤り。誤っている部分（span）、訂正（suggestion）、分類（category）、日本語の説明（explanation）
+- key_phrases: 模範解答の中で覚えておきたい重要な英語の語句2〜3個と、その日本語の意味
 
 正確に評価してください。間違いがあれば指摘し、改善方法を説明してください。`
+
+// GenerateQuestionPromptEnJa is the prompt template for generating English
+// sentences to translate into Japanese
+const GenerateQuestionPromptEnJa = `あなたは英語学習アシスタントです。和訳練習用の英語の文を生成してください。
+
+テーマ: %s
+難易度: %s
+
+%sルール:
+- 英語の文のみを出力してください（それ以外は何も出力しないでください）
+- 自然でよく使われる表現にしてください
+- 難易度に合わせてください
+  - beginner（初級）: シンプルな文法、基本的な語彙
+  - intermediate（中級）: 複文、一般的な表現
+  - advanced（上級）: 複雑な文法、慣用句、ニュアンスのある表現
+  - A1〜C2 の場合は CEFR のその段階の学習者が使いこなせるべき文法と語彙にしてください
+
+英語の文を出力してください:`
+
+// GenerateHintPromptEnJa is the prompt template for generating hints for
+// English to Japanese questions
+const GenerateHintPromptEnJa = `あなたは英語学習アシスタントです。次の英語の文を和訳しようとしている学習者にヒントを出してください。
+
+英語の文: %s
+難易度: %s
+
+ルール:
+- 和訳の答えそのものは絶対に書かないでください
+- 意味を取るのが難しい英単語・フレーズを2〜3個と、文の構造（文法パターン）を1つ説明してください
+- 日本語で簡潔に、箇条書きで出力してください（それ以外は何も出力しないでください）
+
+ヒントを出力してください:`
+
+// GenerateModelAnswerPromptEnJa is the prompt template for generating model
+// answers for English to Japanese questions
+const GenerateModelAnswerPromptEnJa = `あなたは英語学習アシスタントです。次の英語の文の自然な和訳を1つ示してください。
+
+英語の文: %s
+
+和訳のみを出力してください（それ以外は何も出力しないでください）:`
+
+// EvaluateAnswerPromptEnJa is the prompt template for evaluating Japanese
+// translations of English sentences
+const EvaluateAnswerPromptEnJa = `あなたは英語学習アシスタントです。ユーザーの和訳を評価してください。
+
+英語の文: %s
+ユーザーの回答: %s
+
+submit_evaluation ツールを使って評価結果を返してください:
+- score: 0-100の数値
+- model_answer: あなたの理想的な和訳
+- feedback: 日本語での詳細なフィードバック。英文の読み取りの誤り（単語の意味、文法構造の解釈）と、日本語としての自然さを含めてください
+- corrections: 回答中の個々の誤り。誤っている部分（span）、訂正（suggestion）、分類（category）、日本語の説明（explanation）
+- key_phrases: 英語の文の中で覚えておきたい重要な語句2〜3個と、その日本語の意味
+
+正確に評価してください。英文の意味を取り違えている部分があれば指摘し、正しい読み方を説明してください。`
+
+// directionPrompts holds the prompt templates for one translation direction
+type directionPrompts struct {
+	question    string
+	hint        string
+	modelAnswer string
+	evaluate    string
+}
+
+// promptsFor returns the prompt templates for a direction, defaulting to
+// Japanese to English
+func promptsFor(direction Direction) directionPrompts {
+	if direction == EnglishToJapanese {
+		return directionPrompts{
+			question:    GenerateQuestionPromptEnJa,
+			hint:        GenerateHintPromptEnJa,
+			modelAnswer: GenerateModelAnswerPromptEnJa,
+			evaluate:    EvaluateAnswerPromptEnJa,
+		}
+	}
+	return directionPrompts{
+		question:    GenerateQuestionPrompt,
+		hint:        GenerateHintPrompt,
+		modelAnswer: GenerateModelAnswerPrompt,
+		evaluate:    EvaluateAnswerPrompt,
+	}
+}
+
+// GenerateClozePrompt is the prompt template for generating fill-in-the-blank exercises
+const GenerateClozePrompt = `あなたは英語学習アシスタントです。穴埋め問題を1問作成してください。
+
+テーマ: %s
+難易度: %s
+
+submit_exercise ツールを使って返してください:
+- prompt: 重要な単語1語を ___ に置き換えた英文
+- context: 完成した英文の日本語訳
+- choices: 空配列
+- answer: 空欄に入る語
+- alternates: 空欄に入っても正しい別の語。なければ空配列
+
+空欄の答えが1つに定まるようにしてください。`
+
+// GenerateChoicePrompt is the prompt template for generating multiple choice exercises
+const GenerateChoicePrompt = `あなたは英語学習アシスタントです。4択問題を1問作成してください。
+
+テーマ: %s
+難易度: %s
+
+submit_exercise ツールを使って返してください:
+- prompt: 1か所を ___ にした英文
+- context: 完成した英文の日本語訳
+- choices: 空欄に入る選択肢4つ（正解1つと、学習者が間違えやすい誤答3つ）。順番はランダムにしてください
+- answer: 正解の選択肢（choices の1つと完全に一致させてください）
+- alternates: 空配列
+
+正解が1つだけになるようにしてください。`
+
+// GenerateReorderPrompt is the prompt template for generating word reordering exercises
+const GenerateReorderPrompt = `あなたは英語学習アシスタントです。並べ替え問題を1問作成してください。
+
+テーマ: %s
+難易度: %s
+
+submit_exercise ツールを使って返してください:
+- prompt: 英文の単語をランダムな順番に並べ、" / " で区切ったもの（文頭の大文字と文末の句読点は取り除いてください）
+- context: 完成した英文の日本語訳
+- choices: 空配列
+- answer: 完成した英文
+- alternates: すべての単語を使った、ほかに正しい語順の英文。なければ空配列`
+
+// GenerateCorrectionPrompt is the prompt template for generating error correction exercises
+const GenerateCorrectionPrompt = `あなたは英語学習アシスタントです。誤り訂正問題を1問作成してください。
+
+テーマ: %s
+難易度: %s
+
+submit_exercise ツールを使って返してください:
+- prompt: 文法または語法の誤りを1つだけ含む英文
+- context: 正しい英文の日本語訳
+- choices: 空配列
+- answer: 誤りを直した英文
+- alternates: 空配列
+
+誤りは学習者がよく間違える箇所にしてください。`
+
+// EvaluateClozePrompt is the prompt template for grading fill-in-the-blank answers
+const EvaluateClozePrompt = `あなたは英語学習アシスタントです。穴埋め問題へのユーザーの回答を評価してください。
+
+%s
+ユーザーの回答: %s
+
+submit_evaluation ツールを使って評価結果を返してください:
+- score: 正解なら100、意味は通るが不自然なら50前後、誤りなら0
+- model_answer: 空欄を埋めた完成した英文
+- feedback: 日本語でのフィードバック。なぜその語が入るのかを説明してください
+- corrections: 回答の誤り。誤りがなければ空配列`
+
+// EvaluateChoicePrompt is the prompt template for grading multiple choice answers
+const EvaluateChoicePrompt = `あなたは英語学習アシスタントです。4択問題へのユーザーの回答を評価してください。
+
+%s
+ユーザーの回答: %s
+
+submit_evaluation ツールを使って評価結果を返してください:
+- score: 正解なら100、不正解なら0
+- model_answer: 正解の選択肢を入れて完成した英文
+- feedback: 日本語でのフィードバック。正解の理由と、他の選択肢が誤りである理由を説明してください
+- corrections: 回答の誤り。誤りがなければ空配列`
+
+// EvaluateReorderPrompt is the prompt template for grading word reordering answers
+const EvaluateReorderPrompt = `あなたは英語学習アシスタントです。並べ替え問題へのユーザーの回答を評価してください。
+
+%s
+ユーザーの回答: %s
+
+submit_evaluation ツールを使って評価結果を返してください:
+- score: 0-100の数値。すべての単語を使い正しい語順なら100。大文字・小文字や句読点の違いは減点しないでください
+- model_answer: 正しい語順の英文
+- feedback: 日本語でのフィードバック。語順の決まり（文法）を説明してください
+- corrections: 回答の誤り。誤りがなければ空配列`
+
+// EvaluateCorrectionPrompt is the prompt template for grading error correction answers
+const EvaluateCorrectionPrompt = `あなたは英語学習アシスタントです。誤り訂正問題へのユーザーの回答を評価してください。ユーザーは問題文の誤りを直した英文を回答します。
+
+%s
+ユーザーの回答: %s
+
+submit_evaluation ツールを使って評価結果を返してください:
+- score: 0-100の数値。誤りを正しく直し、新たな誤りがなければ100
+- model_answer: 誤りを直した英文
+- feedback: 日本語でのフィードバック。どこが誤りで、なぜそう直すのかを説明してください
+- corrections: 回答に残っている誤り。誤りがなければ空配列
+- key_phrases: 正しい英文の中で覚えておきたい重要な語句1〜2個と、その日本語の意味`
@@ -0,0 +1,63 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// maxAttempts is the number of times a model request is made before its error
+// is returned
+const maxAttempts = 2
+
+// statusError is an error response from an OpenAI-compatible API
+type statusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("API returned %s: %s", e.Status, e.Body)
+}
+
+// withRetry makes a model request up to maxAttempts times. Both clients use it
+// for every request that parses the model's output.
+func withRetry[T any](request func() (T, error)) (T, error) {
+	var result T
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		result, err = request()
+		if err == nil || !retryable(err) {
+			return result, err
+		}
+	}
+	return result, err
+}
+
+// retryable reports whether a failed request may succeed when repeated:
+// malformed output, network failures, rate limits and server errors may;
+// other API errors such as a bad key, and cancellation, will not
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var claudeErr *anthropic.Error
+	if errors.As(err, &claudeErr) {
+		return transientStatus(claudeErr.StatusCode)
+	}
+	var apiErr *statusError
+	if errors.As(err, &apiErr) {
+		return transientStatus(apiErr.StatusCode)
+	}
+	return true
+}
+
+// transientStatus reports whether an HTTP status signals a temporary failure
+func transientStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
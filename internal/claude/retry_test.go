@@ -0,0 +1,34 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"malformed output", errors.New("invalid evaluation JSON"), true},
+		{"Claude overloaded", fmt.Errorf("failed to evaluate answer: %w", &anthropic.Error{StatusCode: 529}), true},
+		{"Claude rate limit", &anthropic.Error{StatusCode: 429}, true},
+		{"Claude bad key", fmt.Errorf("failed to generate exercise: %w", &anthropic.Error{StatusCode: 401}), false},
+		{"API bad request", &statusError{StatusCode: 400}, false},
+		{"API server error", &statusError{StatusCode: 502}, true},
+		{"cancelled", fmt.Errorf("failed to evaluate answer: %w", context.Canceled), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.err); got != tt.want {
+				t.Errorf("retryable(%v) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
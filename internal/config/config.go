@@ -7,7 +7,9 @@ import (
 
 type Config struct {
 	Discord  DiscordConfig
+	LLM      LLMConfig
 	Claude   ClaudeConfig
+	OpenAI   OpenAIConfig
 	Schedule ScheduleConfig
 	Database DatabaseConfig
 }
@@ -22,6 +24,18 @@ type ClaudeConfig struct {
 	Model  string
 }
 
+// LLMConfig selects the model backend: "claude", "openai" or "fake"
+type LLMConfig struct {
+	Provider string
+}
+
+// OpenAIConfig configures an OpenAI-compatible chat completions API
+type OpenAIConfig struct {
+	APIKey  string
+	Model   string
+	BaseURL string
+}
+
 type ScheduleConfig struct {
 	IntervalMinutes int
 }
@@ -49,15 +63,38 @@ func Load() *Config {
 		model = "claude-sonnet-4-20250514"
 	}
 
+	provider := os.Getenv("LLM_PROVIDER")
+	if provider == "" {
+		provider = "claude"
+	}
+
+	openAIModel := os.Getenv("OPENAI_MODEL")
+	if openAIModel == "" {
+		openAIModel = "gpt-4o-mini"
+	}
+
+	openAIBaseURL := os.Getenv("OPENAI_BASE_URL")
+	if openAIBaseURL == "" {
+		openAIBaseURL = "https://api.openai.com/v1"
+	}
+
 	return &Config{
 		Discord: DiscordConfig{
 			Token:     os.Getenv("DISCORD_TOKEN"),
 			ChannelID: os.Getenv("DISCORD_CHANNEL_ID"),
 		},
+		LLM: LLMConfig{
+			Provider: provider,
+		},
 		Claude: ClaudeConfig{
 			APIKey: os.Getenv("CLAUDE_API_KEY"),
 			Model:  model,
 		},
+		OpenAI: OpenAIConfig{
+			APIKey:  os.Getenv("OPENAI_API_KEY"),
+			Model:   openAIModel,
+			BaseURL: openAIBaseURL,
+		},
 		Schedule: ScheduleConfig{
 			IntervalMinutes: interval,
 		},
@@ -13,6 +13,10 @@ func TestLoad(t *testing.T) {
 	os.Setenv("CLAUDE_MODEL", "claude-sonnet-4-20250514")
 	os.Setenv("SCHEDULE_INTERVAL", "30")
 	os.Setenv("DATABASE_PATH", "./test.db")
+	os.Setenv("LLM_PROVIDER", "openai")
+	os.Setenv("OPENAI_API_KEY", "test-openai-key")
+	os.Setenv("OPENAI_MODEL", "gpt-test")
+	os.Setenv("OPENAI_BASE_URL", "http://localhost:8080/v1")
 	defer func() {
 		os.Unsetenv("DISCORD_TOKEN")
 		os.Unsetenv("DISCORD_CHANNEL_ID")
@@ -20,6 +24,10 @@ func TestLoad(t *testing.T) {
 		os.Unsetenv("CLAUDE_MODEL")
 		os.Unsetenv("SCHEDULE_INTERVAL")
 		os.Unsetenv("DATABASE_PATH")
+		os.Unsetenv("LLM_PROVIDER")
+		os.Unsetenv("OPENAI_API_KEY")
+		os.Unsetenv("OPENAI_MODEL")
+		os.Unsetenv("OPENAI_BASE_URL")
 	}()
 
 	cfg := Load()
@@ -42,6 +50,18 @@ func TestLoad(t *testing.T) {
 	if cfg.Database.Path != "./test.db" {
 		t.Errorf("Expected path './test.db', got '%s'", cfg.Database.Path)
 	}
+	if cfg.LLM.Provider != "openai" {
+		t.Errorf("Expected provider 'openai', got '%s'", cfg.LLM.Provider)
+	}
+	if cfg.OpenAI.APIKey != "test-openai-key" {
+		t.Errorf("Expected openai api_key 'test-openai-key', got '%s'", cfg.OpenAI.APIKey)
+	}
+	if cfg.OpenAI.Model != "gpt-test" {
+		t.Errorf("Expected openai model 'gpt-test', got '%s'", cfg.OpenAI.Model)
+	}
+	if cfg.OpenAI.BaseURL != "http://localhost:8080/v1" {
+		t.Errorf("Expected openai base_url 'http://localhost:8080/v1', got '%s'", cfg.OpenAI.BaseURL)
+	}
 }
 
 func TestLoad_Defaults(t *testing.T) {
@@ -52,6 +72,9 @@ func TestLoad_Defaults(t *testing.T) {
 	os.Unsetenv("CLAUDE_MODEL")
 	os.Unsetenv("SCHEDULE_INTERVAL")
 	os.Unsetenv("DATABASE_PATH")
+	os.Unsetenv("LLM_PROVIDER")
+	os.Unsetenv("OPENAI_MODEL")
+	os.Unsetenv("OPENAI_BASE_URL")
 
 	cfg := Load()
 
@@ -65,6 +88,15 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.Database.Path != "./english_quiz.db" {
 		t.Errorf("Expected default path './english_quiz.db', got '%s'", cfg.Database.Path)
 	}
+	if cfg.LLM.Provider != "claude" {
+		t.Errorf("Expected default provider 'claude', got '%s'", cfg.LLM.Provider)
+	}
+	if cfg.OpenAI.Model != "gpt-4o-mini" {
+		t.Errorf("Expected default openai model 'gpt-4o-mini', got '%s'", cfg.OpenAI.Model)
+	}
+	if cfg.OpenAI.BaseURL != "https://api.openai.com/v1" {
+		t.Errorf("Expected default openai base_url 'https://api.openai.com/v1', got '%s'", cfg.OpenAI.BaseURL)
+	}
 }
 
 func TestLoad_InvalidInterval(t *testing.T) {
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"sort"
+	"time"
+)
+
+// weaknessWindow is the length of the recent period GetWeaknesses compares
+// with the period before it
+const weaknessWindow = 14 * 24 * time.Hour
+
+// AnswerError is one classified error in an answer
+type AnswerError struct {
+	Category   string
+	Span       string
+	Suggestion string
+}
+
+// Weakness counts a user's errors of one category
+type Weakness struct {
+	Category string
+	Total    int
+	Recent   int         // errors in the last 14 days
+	Previous int         // errors in the 14 days before that
+	Example  AnswerError // most recent error of the category
+}
+
+// SaveAnswerErrors records the classified errors of an answer
+func (db *DB) SaveAnswerErrors(answerID int64, errs []AnswerError) error {
+	for _, e := range errs {
+		_, err := db.conn.Exec(
+			"INSERT INTO answer_errors (answer_id, category, span, suggestion) VALUES (?, ?, ?, ?)",
+			answerID, e.Category, e.Span, e.Suggestion,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetWeaknesses returns a user's most frequent error categories, ties going to
+// the category seen most recently, either from answers given in the guild or
+// across all guilds and DMs. Like stats, answers given after revealing the
+// model answer are not counted.
+func (db *DB) GetWeaknesses(discordID, guildID string, scope StatsScope, limit int) ([]Weakness, error) {
+	where := "a.discord_id = ? AND a.guild_id = ?"
+	args := []any{discordID, guildID}
+	if scope == ScopeGlobal {
+		where = "a.discord_id = ?"
+		args = args[:1]
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT e.category, e.span, e.suggestion, a.answered_at
+		FROM answer_errors AS e
+		JOIN answers AS a ON a.id = e.answer_id
+		WHERE a.revealed = 0 AND `+where+`
+		ORDER BY e.id DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	byCategory := make(map[string]*Weakness)
+	var weaknesses []*Weakness
+	for rows.Next() {
+		var e AnswerError
+		var answeredAt time.Time
+		if err := rows.Scan(&e.Category, &e.Span, &e.Suggestion, &answeredAt); err != nil {
+			return nil, err
+		}
+
+		w, ok := byCategory[e.Category]
+		if !ok {
+			// Rows are newest first, so the first error seen is the example
+			w = &Weakness{Category: e.Category, Example: e}
+			byCategory[e.Category] = w
+			weaknesses = append(weaknesses, w)
+		}
+		w.Total++
+		switch age := now.Sub(answeredAt); {
+		case age < weaknessWindow:
+			w.Recent++
+		case age < 2*weaknessWindow:
+			w.Previous++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(weaknesses, func(i, j int) bool {
+		return weaknesses[i].Total > weaknesses[j].Total
+	})
+
+	result := make([]Weakness, 0, min(len(weaknesses), limit))
+	for _, w := range weaknesses[:min(len(weaknesses), limit)] {
+		result = append(result, *w)
+	}
+	return result, nil
+}
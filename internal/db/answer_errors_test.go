@@ -0,0 +1,57 @@
+package db
+
+import "testing"
+
+func TestWeaknesses(t *testing.T) {
+	db := newTestDB(t)
+
+	qID, _ := db.SaveQuestion("guild-1", "問題", "beginner", "テスト", DirectionJaEn)
+
+	old, _ := db.SaveAnswer("12345", "guild-1", qID, "a", "a", 40, "")
+	db.SaveAnswerErrors(old, []AnswerError{
+		{Category: "article", Span: "a", Suggestion: "the"},
+		{Category: "tense", Span: "go", Suggestion: "went"},
+	})
+	db.conn.Exec("UPDATE answers SET answered_at = datetime('now', '-20 days') WHERE id = ?", old)
+
+	recent, _ := db.SaveAnswer("12345", "guild-1", qID, "b", "b", 60, "")
+	db.SaveAnswerErrors(recent, []AnswerError{
+		{Category: "article", Span: "an", Suggestion: "a"},
+		{Category: "article", Span: "the", Suggestion: ""},
+	})
+
+	other, _ := db.SaveAnswer("12345", "guild-2", qID, "c", "c", 60, "")
+	db.SaveAnswerErrors(other, []AnswerError{{Category: "preposition", Span: "in", Suggestion: "on"}})
+
+	weaknesses, err := db.GetWeaknesses("12345", "guild-1", ScopeServer, 5)
+	if err != nil {
+		t.Fatalf("Failed to get weaknesses: %v", err)
+	}
+	if len(weaknesses) != 2 {
+		t.Fatalf("Expected 2 categories in the server, got %+v", weaknesses)
+	}
+
+	article := weaknesses[0]
+	if article.Category != "article" || article.Total != 3 || article.Recent != 2 || article.Previous != 1 {
+		t.Errorf("Unexpected article counts: %+v", article)
+	}
+	if article.Example.Span != "the" {
+		t.Errorf("Expected the latest article error as example, got %+v", article.Example)
+	}
+	if tense := weaknesses[1]; tense.Category != "tense" || tense.Recent != 0 || tense.Previous != 1 {
+		t.Errorf("Unexpected tense counts: %+v", tense)
+	}
+
+	global, _ := db.GetWeaknesses("12345", "guild-1", ScopeGlobal, 2)
+	if len(global) != 2 || global[0].Category != "article" {
+		t.Errorf("Expected the top 2 categories across servers, got %+v", global)
+	}
+
+	// Errors in answers given after revealing the model answer are not counted
+	db.RecordReveal("67890", "guild-1", qID)
+	revealed, _ := db.SaveAnswer("67890", "guild-1", qID, "d", "d", 0, "")
+	db.SaveAnswerErrors(revealed, []AnswerError{{Category: "spelling", Span: "teh", Suggestion: "the"}})
+	if w, _ := db.GetWeaknesses("67890", "guild-1", ScopeServer, 5); len(w) != 0 {
+		t.Errorf("Expected no weaknesses from revealed answers, got %+v", w)
+	}
+}
@@ -0,0 +1,85 @@
+package db
+
+import "database/sql"
+
+// Assist records whether a user took a hint or revealed the model answer for a question
+type Assist struct {
+	DiscordID  string
+	QuestionID int64
+	HintUsed   bool
+	Revealed   bool
+}
+
+// RecordHint marks that a user asked for a hint to a question
+func (db *DB) RecordHint(discordID, guildID string, questionID int64) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO assists (discord_id, question_id, guild_id, hint_used, hint_pending) VALUES (?, ?, ?, 1, 1)
+		ON CONFLICT (discord_id, question_id) DO UPDATE SET hint_used = 1, hint_pending = 1
+	`, discordID, questionID, guildID)
+	return err
+}
+
+// RecordReveal marks that a user revealed the model answer to a question
+func (db *DB) RecordReveal(discordID, guildID string, questionID int64) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO assists (discord_id, question_id, guild_id, revealed, reveal_pending) VALUES (?, ?, ?, 1, 1)
+		ON CONFLICT (discord_id, question_id) DO UPDATE SET revealed = 1, reveal_pending = 1
+	`, discordID, questionID, guildID)
+	return err
+}
+
+// RecordAnswerSeen marks that a user was shown the model answer to a question
+// with the evaluation of their answer. Like a reveal, it makes their later
+// answers practice until /review serves the question again, but it is not
+// counted as a reveal in /stats.
+func (db *DB) RecordAnswerSeen(discordID, guildID string, questionID int64) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO assists (discord_id, question_id, guild_id, reveal_pending) VALUES (?, ?, ?, 1)
+		ON CONFLICT (discord_id, question_id) DO UPDATE SET reveal_pending = 1
+	`, discordID, questionID, guildID)
+	return err
+}
+
+// ResetAssist lets a user's next answer to a question count as a fresh
+// attempt. The help they took is kept for /stats but no longer applies.
+func (db *DB) ResetAssist(discordID string, questionID int64) error {
+	_, err := db.conn.Exec(
+		"UPDATE assists SET hint_pending = 0, reveal_pending = 0 WHERE discord_id = ? AND question_id = ?",
+		discordID, questionID,
+	)
+	return err
+}
+
+// GetAssist returns the help a user has taken on a question that applies to
+// their next answer. Users who took none get an Assist with both flags unset.
+func (db *DB) GetAssist(discordID string, questionID int64) (*Assist, error) {
+	a := &Assist{DiscordID: discordID, QuestionID: questionID}
+	row := db.conn.QueryRow(
+		"SELECT hint_pending, reveal_pending FROM assists WHERE discord_id = ? AND question_id = ?",
+		discordID, questionID,
+	)
+
+	var hintUsed, revealed int
+	err := row.Scan(&hintUsed, &revealed)
+	if err == sql.ErrNoRows {
+		return a, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	a.HintUsed = hintUsed == 1
+	a.Revealed = revealed == 1
+	return a, nil
+}
+
+// SaveQuestionHint caches the generated hint for a question
+func (db *DB) SaveQuestionHint(questionID int64, hint string) error {
+	_, err := db.conn.Exec("UPDATE questions SET hint = ? WHERE id = ?", hint, questionID)
+	return err
+}
+
+// SaveQuestionModelAnswer caches the generated model answer for a question
+func (db *DB) SaveQuestionModelAnswer(questionID int64, modelAnswer string) error {
+	_, err := db.conn.Exec("UPDATE questions SET model_answer = ? WHERE id = ?", modelAnswer, questionID)
+	return err
+}
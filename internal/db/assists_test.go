@@ -0,0 +1,76 @@
+package db
+
+import "testing"
+
+func TestAssists(t *testing.T) {
+	db := newTestDB(t)
+
+	qA, _ := db.SaveQuestion("guild-1", "問題A", "beginner", "テスト", DirectionJaEn)
+	qB, _ := db.SaveQuestion("guild-1", "問題B", "beginner", "テスト", DirectionJaEn)
+
+	a, err := db.GetAssist("12345", qA)
+	if err != nil {
+		t.Fatalf("Failed to get assist: %v", err)
+	}
+	if a.HintUsed || a.Revealed {
+		t.Errorf("Expected no assist yet, got %+v", a)
+	}
+
+	db.RecordHint("12345", "guild-1", qA)
+	db.RecordHint("12345", "guild-1", qB)
+	db.RecordReveal("12345", "guild-1", qB)
+
+	a, _ = db.GetAssist("12345", qB)
+	if !a.HintUsed || !a.Revealed {
+		t.Errorf("Expected hint and reveal on question B, got %+v", a)
+	}
+
+	// Answers to a revealed question are not counted as attempts
+	db.SaveAnswer("12345", "guild-1", qA, "a", "a", 70, "")
+	db.SaveAnswer("12345", "guild-1", qB, "b", "b", 100, "")
+
+	stats, err := db.GetUserStats("12345", "guild-1", ScopeServer)
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats.TotalAnswers != 1 || stats.HighestScore != 70 {
+		t.Errorf("Expected only the unrevealed answer, got %+v", stats)
+	}
+	if stats.HintsUsed != 2 || stats.Revealed != 1 {
+		t.Errorf("Expected 2 hints and 1 reveal, got %+v", stats)
+	}
+
+	entries, total, _ := db.GetLeaderboard("guild-1", PeriodAll, MetricAverage, 10, 0)
+	if total != 1 || entries[0].Value != 70 {
+		t.Errorf("Expected revealed answer left off the leaderboard, got %+v", entries)
+	}
+
+	// A reset makes the next answer a fresh attempt but keeps the history
+	if err := db.ResetAssist("12345", qB); err != nil {
+		t.Fatalf("Failed to reset assist: %v", err)
+	}
+	if a, _ = db.GetAssist("12345", qB); a.HintUsed || a.Revealed {
+		t.Errorf("Expected no help to apply after a reset, got %+v", a)
+	}
+	db.SaveAnswer("12345", "guild-1", qB, "b", "b", 90, "")
+	stats, _ = db.GetUserStats("12345", "guild-1", ScopeServer)
+	if stats.TotalAnswers != 2 || stats.HintsUsed != 2 || stats.Revealed != 1 {
+		t.Errorf("Expected the answer counted and the help kept, got %+v", stats)
+	}
+}
+
+func TestQuestionCache(t *testing.T) {
+	db := newTestDB(t)
+
+	qID, _ := db.SaveQuestion("", "テスト問題", "beginner", "テスト", DirectionJaEn)
+	db.SaveQuestionHint(qID, "- test")
+	db.SaveQuestionModelAnswer(qID, "This is a test.")
+
+	q, err := db.GetQuestion(qID)
+	if err != nil {
+		t.Fatalf("Failed to get question: %v", err)
+	}
+	if q.Hint != "- test" || q.ModelAnswer != "This is a test." {
+		t.Errorf("Expected cached hint and model answer, got %+v", q)
+	}
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Challenge is a question posted to a channel that collects everyone's
+// replies until EndsAt and then ranks them
+type Challenge struct {
+	MessageID  string
+	GuildID    string
+	ChannelID  string
+	QuestionID int64
+	EndsAt     time.Time
+	Closed     bool
+	CreatedAt  time.Time
+}
+
+// ChallengeEntry is one user's answer to a challenge
+type ChallengeEntry struct {
+	MessageID   string
+	DiscordID   string
+	Answer      string
+	SubmittedAt time.Time
+}
+
+// SaveChallenge records a newly posted challenge
+func (db *DB) SaveChallenge(c *Challenge) error {
+	_, err := db.conn.Exec(
+		"INSERT INTO challenges (message_id, guild_id, channel_id, question_id, ends_at) VALUES (?, ?, ?, ?, ?)",
+		c.MessageID, c.GuildID, c.ChannelID, c.QuestionID, c.EndsAt.UTC().Format(timeLayout),
+	)
+	return err
+}
+
+// GetChallenge looks up a challenge by its message ID, returning nil if the message is not a challenge
+func (db *DB) GetChallenge(messageID string) (*Challenge, error) {
+	c := &Challenge{MessageID: messageID}
+	row := db.conn.QueryRow(
+		"SELECT guild_id, channel_id, question_id, ends_at, closed, created_at FROM challenges WHERE message_id = ?",
+		messageID,
+	)
+
+	var closed int
+	err := row.Scan(&c.GuildID, &c.ChannelID, &c.QuestionID, &c.EndsAt, &closed, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Closed = closed == 1
+	return c, nil
+}
+
+// GetOpenChallenges returns all challenges that have not been closed yet
+func (db *DB) GetOpenChallenges() ([]*Challenge, error) {
+	rows, err := db.conn.Query(
+		"SELECT message_id, guild_id, channel_id, question_id, ends_at, created_at FROM challenges WHERE closed = 0",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var challenges []*Challenge
+	for rows.Next() {
+		c := &Challenge{}
+		if err := rows.Scan(&c.MessageID, &c.GuildID, &c.ChannelID, &c.QuestionID, &c.EndsAt, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		challenges = append(challenges, c)
+	}
+	return challenges, rows.Err()
+}
+
+// CloseChallenge marks a challenge closed. It reports false if the challenge
+// was already closed, so results are only posted once.
+func (db *DB) CloseChallenge(messageID string) (bool, error) {
+	result, err := db.conn.Exec("UPDATE challenges SET closed = 1 WHERE message_id = ? AND closed = 0", messageID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// SaveChallengeEntry records a user's answer to a challenge, replacing any
+// earlier answer from the same user
+func (db *DB) SaveChallengeEntry(messageID, discordID, answer string) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO challenge_entries (message_id, discord_id, answer, submitted_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (message_id, discord_id) DO UPDATE SET
+			answer = excluded.answer,
+			submitted_at = excluded.submitted_at
+	`, messageID, discordID, answer, time.Now().UTC().Format(timeLayout))
+	return err
+}
+
+// GetChallengeEntries returns the answers to a challenge in submission order
+func (db *DB) GetChallengeEntries(messageID string) ([]*ChallengeEntry, error) {
+	rows, err := db.conn.Query(
+		"SELECT discord_id, answer, submitted_at FROM challenge_entries WHERE message_id = ? ORDER BY submitted_at, discord_id",
+		messageID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*ChallengeEntry
+	for rows.Next() {
+		e := &ChallengeEntry{MessageID: messageID}
+		if err := rows.Scan(&e.DiscordID, &e.Answer, &e.SubmittedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChallenges(t *testing.T) {
+	db := newTestDB(t)
+
+	qID, _ := db.SaveQuestion("guild-1", "テスト問題", "beginner", "テスト", DirectionJaEn)
+	endsAt := time.Now().Add(10 * time.Minute)
+	err := db.SaveChallenge(&Challenge{MessageID: "msg-1", GuildID: "guild-1", ChannelID: "chan-1", QuestionID: qID, EndsAt: endsAt})
+	if err != nil {
+		t.Fatalf("Failed to save challenge: %v", err)
+	}
+
+	c, err := db.GetChallenge("msg-1")
+	if err != nil || c == nil {
+		t.Fatalf("Failed to get challenge: %v", err)
+	}
+	if c.QuestionID != qID || c.Closed || c.EndsAt.Unix() != endsAt.Unix() {
+		t.Errorf("Unexpected challenge: %+v", c)
+	}
+
+	if c, _ := db.GetChallenge("missing"); c != nil {
+		t.Errorf("Expected nil for unknown message, got %+v", c)
+	}
+
+	// A later answer from the same user replaces the earlier one
+	db.SaveChallengeEntry("msg-1", "alice", "first")
+	db.SaveChallengeEntry("msg-1", "bob", "hello")
+	db.SaveChallengeEntry("msg-1", "alice", "second")
+
+	entries, err := db.GetChallengeEntries("msg-1")
+	if err != nil {
+		t.Fatalf("Failed to get entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.DiscordID == "alice" && e.Answer != "second" {
+			t.Errorf("Expected alice's latest answer, got %q", e.Answer)
+		}
+	}
+
+	open, _ := db.GetOpenChallenges()
+	if len(open) != 1 {
+		t.Fatalf("Expected 1 open challenge, got %d", len(open))
+	}
+
+	closed, err := db.CloseChallenge("msg-1")
+	if err != nil || !closed {
+		t.Fatalf("Expected challenge to close, got %v %v", closed, err)
+	}
+	if closed, _ := db.CloseChallenge("msg-1"); closed {
+		t.Error("Expected second close to report false")
+	}
+	if open, _ := db.GetOpenChallenges(); len(open) != 0 {
+		t.Errorf("Expected no open challenges, got %d", len(open))
+	}
+}
@@ -6,63 +6,38 @@ import (
 	_ "modernc.org/sqlite"
 )
 
+// timeLayout matches SQLite's CURRENT_TIMESTAMP format so stored times compare correctly
+const timeLayout = "2006-01-02 15:04:05"
+
 type DB struct {
 	conn *sql.DB
 }
 
-// New creates a new database connection and initializes tables
+// New creates a new database connection and applies pending migrations
 func New(path string) (*DB, error) {
-	conn, err := sql.Open("sqlite", path)
+	db, err := Open(path)
 	if err != nil {
 		return nil, err
 	}
 
-	db := &DB{conn: conn}
-	if err := db.initTables(); err != nil {
-		conn.Close()
+	if _, err := db.Migrate(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
 
+// Open creates a new database connection without touching the schema
+func Open(path string) (*DB, error) {
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{conn: conn}, nil
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
 	return db.conn.Close()
 }
-
-// initTables creates the required tables if they don't exist
-func (db *DB) initTables() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS users (
-		discord_id TEXT PRIMARY KEY,
-		difficulty TEXT DEFAULT 'intermediate',
-		theme TEXT DEFAULT '日常会話',
-		schedule_enabled INTEGER DEFAULT 1,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TABLE IF NOT EXISTS questions (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		japanese TEXT NOT NULL,
-		difficulty TEXT NOT NULL,
-		theme TEXT NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TABLE IF NOT EXISTS answers (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		discord_id TEXT NOT NULL,
-		question_id INTEGER NOT NULL,
-		user_answer TEXT NOT NULL,
-		model_answer TEXT,
-		score INTEGER,
-		feedback TEXT,
-		answered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (question_id) REFERENCES questions(id)
-	);
-	`
-
-	_, err := db.conn.Exec(schema)
-	return err
-}
@@ -3,6 +3,7 @@ package db
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -51,7 +52,7 @@ func TestGetOrCreateUser(t *testing.T) {
 	defer db.Close()
 
 	// First call should create user
-	user, err := db.GetOrCreateUser("12345")
+	user, err := db.GetOrCreateUser("12345", "")
 	if err != nil {
 		t.Fatalf("Failed to get/create user: %v", err)
 	}
@@ -65,12 +66,12 @@ func TestGetOrCreateUser(t *testing.T) {
 	if user.Theme != "日常会話" {
 		t.Errorf("Expected theme '日常会話', got '%s'", user.Theme)
 	}
-	if !user.ScheduleEnabled {
-		t.Error("Expected schedule_enabled true, got false")
+	if user.ScheduleEnabled {
+		t.Error("Expected scheduled quizzes to be opt-in, got schedule_enabled true")
 	}
 
 	// Second call should return existing user
-	user2, err := db.GetOrCreateUser("12345")
+	user2, err := db.GetOrCreateUser("12345", "")
 	if err != nil {
 		t.Fatalf("Failed to get existing user: %v", err)
 	}
@@ -94,19 +95,19 @@ func TestUpdateUserSettings(t *testing.T) {
 	defer db.Close()
 
 	// Create user
-	_, err = db.GetOrCreateUser("12345")
+	_, err = db.GetOrCreateUser("12345", "")
 	if err != nil {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
 	// Update settings
-	err = db.UpdateUserSettings("12345", "advanced", "プログラミング")
+	err = db.UpdateUserSettings("12345", "", "advanced", "プログラミング")
 	if err != nil {
 		t.Fatalf("Failed to update settings: %v", err)
 	}
 
 	// Verify update
-	user, err := db.GetOrCreateUser("12345")
+	user, err := db.GetOrCreateUser("12345", "")
 	if err != nil {
 		t.Fatalf("Failed to get user: %v", err)
 	}
@@ -134,7 +135,7 @@ func TestSaveQuestion(t *testing.T) {
 	defer db.Close()
 
 	// Save question
-	id, err := db.SaveQuestion("これはテストです", "intermediate", "テスト")
+	id, err := db.SaveQuestion("", "これはテストです", "intermediate", "テスト", DirectionJaEn)
 	if err != nil {
 		t.Fatalf("Failed to save question: %v", err)
 	}
@@ -157,6 +158,9 @@ func TestSaveQuestion(t *testing.T) {
 	if q.Theme != "テスト" {
 		t.Errorf("Expected theme 'テスト', got '%s'", q.Theme)
 	}
+	if q.Direction != DirectionJaEn {
+		t.Errorf("Expected direction '%s', got '%s'", DirectionJaEn, q.Direction)
+	}
 }
 
 func TestSaveAnswer(t *testing.T) {
@@ -174,16 +178,19 @@ func TestSaveAnswer(t *testing.T) {
 	defer db.Close()
 
 	// Save question first
-	qID, _ := db.SaveQuestion("テスト問題", "beginner", "テスト")
+	qID, _ := db.SaveQuestion("", "テスト問題", "beginner", "テスト", DirectionJaEn)
 
 	// Save answer
-	err = db.SaveAnswer("12345", qID, "This is a test", "This is a test.", 95, "Great!")
+	aID, err := db.SaveAnswer("12345", "", qID, "This is a test", "This is a test.", 95, "Great!")
 	if err != nil {
 		t.Fatalf("Failed to save answer: %v", err)
 	}
+	if aID == 0 {
+		t.Error("Expected the answer ID")
+	}
 
 	// Verify through stats
-	stats, err := db.GetUserStats("12345")
+	stats, err := db.GetUserStats("12345", "", ScopeServer)
 	if err != nil {
 		t.Fatalf("Failed to get stats: %v", err)
 	}
@@ -211,7 +218,7 @@ func TestGetUserStats(t *testing.T) {
 	defer db.Close()
 
 	// Empty stats
-	stats, err := db.GetUserStats("12345")
+	stats, err := db.GetUserStats("12345", "", ScopeServer)
 	if err != nil {
 		t.Fatalf("Failed to get stats: %v", err)
 	}
@@ -221,12 +228,12 @@ func TestGetUserStats(t *testing.T) {
 	}
 
 	// Add some answers
-	qID, _ := db.SaveQuestion("テスト1", "beginner", "テスト")
-	db.SaveAnswer("12345", qID, "test1", "test1", 80, "Good")
-	db.SaveAnswer("12345", qID, "test2", "test2", 90, "Great")
-	db.SaveAnswer("12345", qID, "test3", "test3", 100, "Perfect")
+	qID, _ := db.SaveQuestion("", "テスト1", "beginner", "テスト", DirectionJaEn)
+	db.SaveAnswer("12345", "", qID, "test1", "test1", 80, "Good")
+	db.SaveAnswer("12345", "", qID, "test2", "test2", 90, "Great")
+	db.SaveAnswer("12345", "", qID, "test3", "test3", 100, "Perfect")
 
-	stats, err = db.GetUserStats("12345")
+	stats, err = db.GetUserStats("12345", "", ScopeServer)
 	if err != nil {
 		t.Fatalf("Failed to get stats: %v", err)
 	}
@@ -242,3 +249,256 @@ func TestGetUserStats(t *testing.T) {
 		t.Errorf("Expected average %.1f, got %.1f", expectedAvg, stats.AverageScore)
 	}
 }
+
+func TestAnswerAttempts(t *testing.T) {
+	db := newTestDB(t)
+
+	qA, _ := db.SaveQuestion("", "テスト1", "beginner", "テスト", DirectionJaEn)
+	qB, _ := db.SaveQuestion("", "テスト2", "beginner", "テスト", DirectionJaEn)
+
+	if a, err := db.GetLastAnswer("12345", qA); err != nil || a != nil {
+		t.Fatalf("Expected no answer yet, got %+v (%v)", a, err)
+	}
+
+	db.SaveAnswer("12345", "", qA, "first", "model", 50, "")
+	db.SaveAnswer("12345", "", qA, "second", "model", 80, "")
+	db.SaveAnswer("12345", "", qB, "first", "model", 90, "")
+	db.SaveAnswer("12345", "", qB, "second", "model", 60, "")
+
+	a, err := db.GetLastAnswer("12345", qA)
+	if err != nil {
+		t.Fatalf("Failed to get last answer: %v", err)
+	}
+	if a.Attempt != 2 || a.UserAnswer != "second" || a.Score != 80 {
+		t.Errorf("Expected the second attempt, got %+v", a)
+	}
+
+	stats, _ := db.GetUserStats("12345", "", ScopeServer)
+	if stats.Improved != 1 {
+		t.Errorf("Expected 1 improved question, got %d", stats.Improved)
+	}
+}
+
+// newTestDB creates a database backed by a temporary file
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	tmpFile, err := os.CreateTemp("", "test-*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	tmpFile.Close()
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	db, err := New(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestGetScheduledUsers(t *testing.T) {
+	db := newTestDB(t)
+
+	db.GetOrCreateUser("111", "")
+	db.UpdateUserSchedule("111", "", true)
+	db.GetOrCreateUser("222", "")
+	db.UpdateUserSettings("222", "", "advanced", "旅行")
+	db.UpdateUserSchedule("222", "", true)
+	db.GetOrCreateUser("333", "")
+
+	users, err := db.GetScheduledUsers()
+	if err != nil {
+		t.Fatalf("Failed to get scheduled users: %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("Expected 2 scheduled users, got %d", len(users))
+	}
+	for _, u := range users {
+		if u.DiscordID == "333" {
+			t.Error("User who never turned on scheduled quizzes should not be returned")
+		}
+		if u.DiscordID == "222" && (u.Difficulty != "advanced" || u.Theme != "旅行") {
+			t.Errorf("Expected user settings to be loaded, got %+v", u)
+		}
+	}
+}
+
+func TestUpdateUserDeliveryTimes(t *testing.T) {
+	db := newTestDB(t)
+
+	user, err := db.GetOrCreateUser("12345", "")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if user.Timezone != "Asia/Tokyo" || user.Schedule != "" {
+		t.Errorf("Expected default timezone and empty schedule, got %q %q", user.Timezone, user.Schedule)
+	}
+
+	if err := db.UpdateUserDeliveryTimes("12345", "", "Europe/London", "weekdays 07:30"); err != nil {
+		t.Fatalf("Failed to update delivery times: %v", err)
+	}
+
+	user, err = db.GetOrCreateUser("12345", "")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if user.Timezone != "Europe/London" {
+		t.Errorf("Expected timezone 'Europe/London', got '%s'", user.Timezone)
+	}
+	if user.Schedule != "weekdays 07:30" {
+		t.Errorf("Expected schedule 'weekdays 07:30', got '%s'", user.Schedule)
+	}
+}
+
+func TestGetUserStats_Streak(t *testing.T) {
+	db := newTestDB(t)
+
+	db.GetOrCreateUser("12345", "")
+	db.UpdateUserDeliveryTimes("12345", "", "UTC", "")
+	qID, _ := db.SaveQuestion("", "テスト", "beginner", "テスト", DirectionJaEn)
+
+	now := time.Now().UTC()
+	for _, daysAgo := range []int{0, 1, 2, 5, 6} {
+		_, err := db.conn.Exec(
+			"INSERT INTO answers (discord_id, question_id, user_answer, score, answered_at) VALUES (?, ?, ?, ?, ?)",
+			"12345", qID, "test", 80, now.AddDate(0, 0, -daysAgo).Format(timeLayout),
+		)
+		if err != nil {
+			t.Fatalf("Failed to insert answer: %v", err)
+		}
+	}
+
+	stats, err := db.GetUserStats("12345", "", ScopeServer)
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+
+	if stats.CurrentStreak != 3 {
+		t.Errorf("Expected current streak 3, got %d", stats.CurrentStreak)
+	}
+	if stats.LongestStreak != 3 {
+		t.Errorf("Expected longest streak 3, got %d", stats.LongestStreak)
+	}
+	if stats.AnswersToday != 1 {
+		t.Errorf("Expected 1 answer today, got %d", stats.AnswersToday)
+	}
+}
+
+func TestUpdateUserDelivery(t *testing.T) {
+	db := newTestDB(t)
+
+	user, _ := db.GetOrCreateUser("12345", "")
+	if user.Delivery != DeliveryChannel {
+		t.Errorf("Expected default delivery '%s', got '%s'", DeliveryChannel, user.Delivery)
+	}
+
+	if err := db.UpdateUserDelivery("12345", "", DeliveryDM); err != nil {
+		t.Fatalf("Failed to update delivery: %v", err)
+	}
+
+	user, _ = db.GetOrCreateUser("12345", "")
+	if user.Delivery != DeliveryDM {
+		t.Errorf("Expected delivery '%s', got '%s'", DeliveryDM, user.Delivery)
+	}
+}
+
+func TestUpdateUserDirection(t *testing.T) {
+	db := newTestDB(t)
+
+	user, _ := db.GetOrCreateUser("12345", "")
+	if user.Direction != DirectionJaEn {
+		t.Errorf("Expected default direction '%s', got '%s'", DirectionJaEn, user.Direction)
+	}
+
+	if err := db.UpdateUserDirection("12345", "", DirectionMixed); err != nil {
+		t.Fatalf("Failed to update direction: %v", err)
+	}
+
+	user, _ = db.GetOrCreateUser("12345", "")
+	if user.Direction != DirectionMixed {
+		t.Errorf("Expected direction '%s', got '%s'", DirectionMixed, user.Direction)
+	}
+}
+
+func TestGuildScopedUsers(t *testing.T) {
+	db := newTestDB(t)
+
+	db.GetOrCreateUser("12345", "guild-a")
+	db.GetOrCreateUser("12345", "guild-b")
+	db.UpdateUserSettings("12345", "guild-a", "advanced", "ビジネス")
+
+	a, _ := db.GetOrCreateUser("12345", "guild-a")
+	b, _ := db.GetOrCreateUser("12345", "guild-b")
+	if a.Theme != "ビジネス" || b.Theme == "ビジネス" {
+		t.Errorf("Expected settings per guild, got %q and %q", a.Theme, b.Theme)
+	}
+
+	qA, _ := db.SaveQuestion("guild-a", "テストA", "beginner", "テスト", DirectionJaEn)
+	qB, _ := db.SaveQuestion("guild-b", "テストB", "beginner", "テスト", DirectionJaEn)
+	db.SaveAnswer("12345", "guild-a", qA, "a", "a", 60, "")
+	db.SaveAnswer("12345", "guild-b", qB, "b", "b", 100, "")
+	db.SaveAnswer("12345", "", qB, "dm", "dm", 80, "")
+
+	q, _ := db.GetQuestion(qA)
+	if q.GuildID != "guild-a" {
+		t.Errorf("Expected question guild 'guild-a', got %q", q.GuildID)
+	}
+
+	tests := []struct {
+		guildID   string
+		scope     StatsScope
+		wantTotal int
+		wantMax   int
+	}{
+		{"guild-a", ScopeServer, 1, 60},
+		{"guild-b", ScopeServer, 1, 100},
+		{"", ScopeServer, 1, 80},
+		{"guild-a", ScopeGlobal, 3, 100},
+	}
+	for _, tt := range tests {
+		stats, err := db.GetUserStats("12345", tt.guildID, tt.scope)
+		if err != nil {
+			t.Fatalf("Failed to get stats: %v", err)
+		}
+		if stats.TotalAnswers != tt.wantTotal || stats.HighestScore != tt.wantMax {
+			t.Errorf("%q %s: expected %d answers max %d, got %d max %d",
+				tt.guildID, tt.scope, tt.wantTotal, tt.wantMax, stats.TotalAnswers, stats.HighestScore)
+		}
+	}
+}
+
+func TestSaveExercise(t *testing.T) {
+	db := newTestDB(t)
+
+	id, err := db.SaveExercise(&Question{
+		GuildID:      "guild-1",
+		Japanese:     "I ___ coffee.",
+		Difficulty:   "beginner",
+		Theme:        "テスト",
+		ExerciseType: "choice",
+		Context:      "コーヒーを飲む",
+		Choices:      []string{"drink", "drinks"},
+		AnswerKey:    "drink",
+	})
+	if err != nil {
+		t.Fatalf("Failed to save exercise: %v", err)
+	}
+
+	q, err := db.GetQuestion(id)
+	if err != nil {
+		t.Fatalf("Failed to get question: %v", err)
+	}
+	if q.ExerciseType != "choice" || q.Context != "コーヒーを飲む" || len(q.Choices) != 2 || q.Choices[1] != "drinks" || q.AnswerKey != "drink" || q.Alternates != nil {
+		t.Errorf("Unexpected exercise: %+v", q)
+	}
+
+	// Translation questions have no choices
+	tID, _ := db.SaveQuestion("", "テスト", "beginner", "テスト", DirectionJaEn)
+	if q, _ := db.GetQuestion(tID); q.ExerciseType != ExerciseTranslation || q.Choices != nil {
+		t.Errorf("Expected a translation question without choices, got %+v", q)
+	}
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetNextFlashcard returns the vocabulary entry a user should drill next:
+// the most overdue one, then entries never drilled. It returns nil if no
+// entry is due.
+func (db *DB) GetNextFlashcard(discordID string) (*VocabularyEntry, error) {
+	e := &VocabularyEntry{DiscordID: discordID}
+	row := db.conn.QueryRow(`
+		SELECT id, phrase, meaning, note, source, COALESCE(question_id, 0), created_at
+		FROM vocabulary
+		WHERE discord_id = ? AND (due_at IS NULL OR due_at <= ?)
+		ORDER BY due_at IS NULL, due_at, id
+		LIMIT 1
+	`, discordID, time.Now().UTC().Format(timeLayout))
+	err := row.Scan(&e.ID, &e.Phrase, &e.Meaning, &e.Note, &e.Source, &e.QuestionID, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// CountDueFlashcards returns how many vocabulary entries are due for a user
+func (db *DB) CountDueFlashcards(discordID string) (int, error) {
+	var count int
+	row := db.conn.QueryRow(
+		"SELECT COUNT(*) FROM vocabulary WHERE discord_id = ? AND (due_at IS NULL OR due_at <= ?)",
+		discordID, time.Now().UTC().Format(timeLayout),
+	)
+	err := row.Scan(&count)
+	return count, err
+}
+
+// GetVocabularyEntry gets one entry of a user's notebook, returning nil if it
+// is not there
+func (db *DB) GetVocabularyEntry(discordID string, id int64) (*VocabularyEntry, error) {
+	e := &VocabularyEntry{ID: id, DiscordID: discordID}
+	row := db.conn.QueryRow(
+		"SELECT phrase, meaning, note, source, COALESCE(question_id, 0), created_at FROM vocabulary WHERE id = ? AND discord_id = ?",
+		id, discordID,
+	)
+	err := row.Scan(&e.Phrase, &e.Meaning, &e.Note, &e.Source, &e.QuestionID, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// RecordFlashcard records whether the user knew a vocabulary entry and
+// reschedules it with the same SM-2 algorithm as reviews
+func (db *DB) RecordFlashcard(discordID string, id int64, knew bool) error {
+	r := &Review{DiscordID: discordID}
+	row := db.conn.QueryRow(
+		"SELECT ease_factor, interval_days, repetitions FROM vocabulary WHERE id = ? AND discord_id = ?",
+		id, discordID,
+	)
+	if err := row.Scan(&r.EaseFactor, &r.Interval, &r.Repetitions); err != nil {
+		return err
+	}
+
+	score := 0
+	if knew {
+		score = 100
+	}
+	r.schedule(score, time.Now())
+
+	_, err := db.conn.Exec(
+		"UPDATE vocabulary SET ease_factor = ?, interval_days = ?, repetitions = ?, due_at = ? WHERE id = ? AND discord_id = ?",
+		r.EaseFactor, r.Interval, r.Repetitions, r.DueAt.UTC().Format(timeLayout), id, discordID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(
+		"INSERT INTO flashcard_reviews (discord_id, vocabulary_id, knew, interval_days) VALUES (?, ?, ?, ?)",
+		discordID, id, knew, r.Interval,
+	)
+	return err
+}
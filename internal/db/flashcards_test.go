@@ -0,0 +1,61 @@
+package db
+
+import "testing"
+
+func TestFlashcards(t *testing.T) {
+	db := newTestDB(t)
+
+	db.AddVocabulary(&VocabularyEntry{DiscordID: "12345", Phrase: "every morning", Meaning: "毎朝", Source: VocabManual})
+	db.AddVocabulary(&VocabularyEntry{DiscordID: "12345", Phrase: "the way to", Meaning: "〜への道", Source: VocabManual})
+
+	if count, _ := db.CountDueFlashcards("12345"); count != 2 {
+		t.Fatalf("Expected new entries to be due, got %d", count)
+	}
+
+	first, err := db.GetNextFlashcard("12345")
+	if err != nil || first == nil || first.Phrase != "every morning" {
+		t.Fatalf("Expected the oldest new entry first, got %+v (%v)", first, err)
+	}
+
+	// Rated cards move out of today's queue whether or not the user knew them
+	if err := db.RecordFlashcard("12345", first.ID, true); err != nil {
+		t.Fatalf("Failed to record flashcard: %v", err)
+	}
+	next, _ := db.GetNextFlashcard("12345")
+	if next == nil || next.Phrase != "the way to" {
+		t.Fatalf("Expected the second entry next, got %+v", next)
+	}
+	db.RecordFlashcard("12345", next.ID, false)
+
+	if e, _ := db.GetNextFlashcard("12345"); e != nil {
+		t.Errorf("Expected no more due cards, got %+v", e)
+	}
+
+	var knownDays, missedDays int
+	db.conn.QueryRow("SELECT interval_days FROM vocabulary WHERE id = ?", first.ID).Scan(&knownDays)
+	db.conn.QueryRow("SELECT interval_days FROM vocabulary WHERE id = ?", next.ID).Scan(&missedDays)
+	if knownDays != 1 || missedDays != 1 {
+		t.Errorf("Expected both cards due tomorrow, got %d and %d days", knownDays, missedDays)
+	}
+
+	// Every rating is kept in the history
+	var ratings, known int
+	db.conn.QueryRow("SELECT COUNT(*), COALESCE(SUM(knew), 0) FROM flashcard_reviews WHERE discord_id = ?", "12345").Scan(&ratings, &known)
+	if ratings != 2 || known != 1 {
+		t.Errorf("Expected 2 ratings with 1 known, got %d and %d", ratings, known)
+	}
+
+	// Overdue cards come before new ones
+	db.AddVocabulary(&VocabularyEntry{DiscordID: "12345", Phrase: "interesting", Source: VocabManual})
+	db.conn.Exec("UPDATE vocabulary SET due_at = '2000-01-01 00:00:00' WHERE id = ?", next.ID)
+	if e, _ := db.GetNextFlashcard("12345"); e == nil || e.ID != next.ID {
+		t.Errorf("Expected the overdue card first, got %+v", e)
+	}
+
+	if e, _ := db.GetVocabularyEntry("67890", first.ID); e != nil {
+		t.Errorf("Expected other users' entries to be hidden, got %+v", e)
+	}
+	if err := db.RecordFlashcard("67890", first.ID, true); err == nil {
+		t.Error("Expected an error rating another user's entry")
+	}
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Guild represents a Discord server's configuration
+type Guild struct {
+	GuildID         string
+	ChannelID       string
+	Theme           string
+	Difficulty      string
+	ScheduleEnabled bool
+	Schedule        string
+	Timezone        string
+	// ChallengeMinutes is how long scheduled quizzes stay open as group
+	// challenges; 0 posts normal quizzes
+	ChallengeMinutes int
+	// Locale is the language feedback, explanations and hints are written
+	// in: "ja" or "en"
+	Locale    string
+	CreatedAt time.Time
+}
+
+// defaultGuild returns the settings used for servers that have not run /setup
+func defaultGuild(guildID string) *Guild {
+	return &Guild{
+		GuildID:    guildID,
+		Theme:      "日常会話",
+		Difficulty: "intermediate",
+		Timezone:   "Asia/Tokyo",
+		Locale:     "ja",
+	}
+}
+
+// GetGuild gets a guild's configuration, returning defaults if it has not been set up
+func (db *DB) GetGuild(guildID string) (*Guild, error) {
+	g := &Guild{GuildID: guildID}
+	row := db.conn.QueryRow(
+		"SELECT channel_id, theme, difficulty, schedule_enabled, schedule, timezone, challenge_minutes, locale, created_at FROM guilds WHERE guild_id = ?",
+		guildID,
+	)
+
+	var scheduleEnabled int
+	err := row.Scan(&g.ChannelID, &g.Theme, &g.Difficulty, &scheduleEnabled, &g.Schedule, &g.Timezone, &g.ChallengeMinutes, &g.Locale, &g.CreatedAt)
+	if err == sql.ErrNoRows {
+		return defaultGuild(guildID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	g.ScheduleEnabled = scheduleEnabled == 1
+	return g, nil
+}
+
+// SaveGuild creates or updates a guild's configuration
+func (db *DB) SaveGuild(g *Guild) error {
+	scheduleEnabled := 0
+	if g.ScheduleEnabled {
+		scheduleEnabled = 1
+	}
+	_, err := db.conn.Exec(`
+		INSERT INTO guilds (guild_id, channel_id, theme, difficulty, schedule_enabled, schedule, timezone, challenge_minutes, locale)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (guild_id) DO UPDATE SET
+			channel_id = excluded.channel_id,
+			theme = excluded.theme,
+			difficulty = excluded.difficulty,
+			schedule_enabled = excluded.schedule_enabled,
+			schedule = excluded.schedule,
+			timezone = excluded.timezone,
+			challenge_minutes = excluded.challenge_minutes,
+			locale = excluded.locale
+	`, g.GuildID, g.ChannelID, g.Theme, g.Difficulty, scheduleEnabled, g.Schedule, g.Timezone, g.ChallengeMinutes, g.Locale)
+	return err
+}
+
+// GetScheduledGuilds returns all guilds with scheduled channel quizzes enabled
+func (db *DB) GetScheduledGuilds() ([]*Guild, error) {
+	rows, err := db.conn.Query(`
+		SELECT guild_id, channel_id, theme, difficulty, schedule_enabled, schedule, timezone, challenge_minutes, locale, created_at
+		FROM guilds WHERE schedule_enabled = 1 AND channel_id != ''
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var guilds []*Guild
+	for rows.Next() {
+		g := &Guild{}
+		var scheduleEnabled int
+		if err := rows.Scan(&g.GuildID, &g.ChannelID, &g.Theme, &g.Difficulty, &scheduleEnabled, &g.Schedule, &g.Timezone, &g.ChallengeMinutes, &g.Locale, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		g.ScheduleEnabled = scheduleEnabled == 1
+		guilds = append(guilds, g)
+	}
+	return guilds, rows.Err()
+}
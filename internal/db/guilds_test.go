@@ -0,0 +1,44 @@
+package db
+
+import "testing"
+
+func TestGuilds(t *testing.T) {
+	db := newTestDB(t)
+
+	// Unconfigured guilds get defaults
+	g, err := db.GetGuild("guild-1")
+	if err != nil {
+		t.Fatalf("Failed to get guild: %v", err)
+	}
+	if g.Theme != "日常会話" || g.Difficulty != "intermediate" || g.ScheduleEnabled || g.Locale != "ja" {
+		t.Errorf("Unexpected defaults: %+v", g)
+	}
+
+	g.ChannelID = "channel-1"
+	g.Theme = "ビジネス"
+	g.ScheduleEnabled = true
+	g.Schedule = "weekdays 12:00"
+	g.Locale = "en"
+	if err := db.SaveGuild(g); err != nil {
+		t.Fatalf("Failed to save guild: %v", err)
+	}
+	if err := db.SaveGuild(&Guild{GuildID: "guild-2", Theme: "旅行", Difficulty: "beginner", Timezone: "UTC"}); err != nil {
+		t.Fatalf("Failed to save guild: %v", err)
+	}
+
+	g, err = db.GetGuild("guild-1")
+	if err != nil {
+		t.Fatalf("Failed to get guild: %v", err)
+	}
+	if g.ChannelID != "channel-1" || g.Theme != "ビジネス" || !g.ScheduleEnabled || g.Schedule != "weekdays 12:00" || g.Locale != "en" {
+		t.Errorf("Guild settings not saved: %+v", g)
+	}
+
+	guilds, err := db.GetScheduledGuilds()
+	if err != nil {
+		t.Fatalf("Failed to get scheduled guilds: %v", err)
+	}
+	if len(guilds) != 1 || guilds[0].GuildID != "guild-1" {
+		t.Errorf("Expected only guild-1 to be scheduled, got %+v", guilds)
+	}
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// LeaderboardPeriod limits which answers a leaderboard counts
+type LeaderboardPeriod string
+
+// Leaderboard periods
+const (
+	PeriodWeek  LeaderboardPeriod = "week"
+	PeriodMonth LeaderboardPeriod = "month"
+	PeriodAll   LeaderboardPeriod = "all"
+)
+
+// LeaderboardMetric is what a leaderboard ranks users by
+type LeaderboardMetric string
+
+// Leaderboard metrics
+const (
+	MetricAverage LeaderboardMetric = "average"
+	MetricAnswers LeaderboardMetric = "answers"
+	MetricStreak  LeaderboardMetric = "streak"
+)
+
+// LeaderboardEntry is one user's rank on a leaderboard
+type LeaderboardEntry struct {
+	Rank      int
+	DiscordID string
+	Value     float64
+	Answers   int
+}
+
+// since returns the earliest answer time counted for the period, or the zero
+// time for all time
+func (p LeaderboardPeriod) since(now time.Time) time.Time {
+	switch p {
+	case PeriodWeek:
+		return now.AddDate(0, 0, -7)
+	case PeriodMonth:
+		return now.AddDate(0, 0, -30)
+	default:
+		return time.Time{}
+	}
+}
+
+// GetLeaderboard ranks the users of a guild by a metric over the answers they
+// gave in the period, leaving out answers to revealed questions. It returns
+// one page of entries and the total number of ranked users. The streak metric
+// ranks users with a current streak, counting only days within the period.
+func (db *DB) GetLeaderboard(guildID string, period LeaderboardPeriod, metric LeaderboardMetric, limit, offset int) ([]LeaderboardEntry, int, error) {
+	now := time.Now()
+	since := period.since(now).UTC().Format(timeLayout)
+
+	var total int
+	row := db.conn.QueryRow(
+		"SELECT COUNT(DISTINCT discord_id) FROM answers WHERE guild_id = ? AND answered_at >= ? AND revealed = 0",
+		guildID, since,
+	)
+	if err := row.Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	var entries []LeaderboardEntry
+	var err error
+	switch metric {
+	case MetricAverage, MetricAnswers:
+		entries, err = db.aggregateLeaderboard(guildID, since, metric, limit, offset)
+	case MetricStreak:
+		entries, total, err = db.streakLeaderboard(guildID, since, now, limit, offset)
+	default:
+		return nil, 0, fmt.Errorf("unknown leaderboard metric %q", metric)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range entries {
+		entries[i].Rank = offset + i + 1
+	}
+	return entries, total, nil
+}
+
+// aggregateLeaderboard ranks users by average score or answer count in SQL
+func (db *DB) aggregateLeaderboard(guildID, since string, metric LeaderboardMetric, limit, offset int) ([]LeaderboardEntry, error) {
+	value := "AVG(score)"
+	if metric == MetricAnswers {
+		value = "COUNT(*)"
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT discord_id, `+value+` AS value, COUNT(*) AS answers
+		FROM answers
+		WHERE guild_id = ? AND answered_at >= ? AND revealed = 0
+		GROUP BY discord_id
+		ORDER BY value DESC, answers DESC, discord_id
+		LIMIT ? OFFSET ?
+	`, guildID, since, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []LeaderboardEntry
+	for rows.Next() {
+		var e LeaderboardEntry
+		if err := rows.Scan(&e.DiscordID, &e.Value, &e.Answers); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// streakLeaderboard ranks users by current streak, leaving out users without
+// one, and returns one page of entries and the number of ranked users.
+// Streaks depend on each user's time zone, so they are computed in Go rather
+// than SQL.
+func (db *DB) streakLeaderboard(guildID, since string, now time.Time, limit, offset int) ([]LeaderboardEntry, int, error) {
+	rows, err := db.conn.Query(
+		"SELECT discord_id, answered_at FROM answers WHERE guild_id = ? AND answered_at >= ? AND revealed = 0",
+		guildID, since,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	answeredAt := make(map[string][]time.Time)
+	for rows.Next() {
+		var discordID string
+		var t time.Time
+		if err := rows.Scan(&discordID, &t); err != nil {
+			return nil, 0, err
+		}
+		answeredAt[discordID] = append(answeredAt[discordID], t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(answeredAt))
+	for discordID, times := range answeredAt {
+		loc, err := db.userLocation(discordID, guildID)
+		if err != nil {
+			return nil, 0, err
+		}
+		current, _ := computeStreaks(times, now, loc)
+		if current == 0 {
+			continue
+		}
+		entries = append(entries, LeaderboardEntry{DiscordID: discordID, Value: float64(current), Answers: len(times)})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		if a.Answers != b.Answers {
+			return a.Answers > b.Answers
+		}
+		return a.DiscordID < b.DiscordID
+	})
+
+	if offset >= len(entries) {
+		return nil, len(entries), nil
+	}
+	end := offset + limit
+	if end > len(entries) {
+		end = len(entries)
+	}
+	return entries[offset:end], len(entries), nil
+}
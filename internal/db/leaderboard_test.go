@@ -0,0 +1,80 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetLeaderboard(t *testing.T) {
+	db := newTestDB(t)
+
+	qID, _ := db.SaveQuestion("guild-1", "テスト", "beginner", "テスト", DirectionJaEn)
+	now := time.Now().UTC()
+	answers := []struct {
+		discordID string
+		guildID   string
+		score     int
+		daysAgo   int
+	}{
+		{"alice", "guild-1", 90, 0},
+		{"alice", "guild-1", 70, 1},
+		{"alice", "guild-1", 80, 2},
+		{"bob", "guild-1", 100, 0},
+		{"carol", "guild-1", 60, 0},
+		{"carol", "guild-1", 60, 20},
+		{"dave", "guild-1", 100, 60},
+		{"erin", "guild-2", 100, 0},
+	}
+	for _, a := range answers {
+		_, err := db.conn.Exec(
+			"INSERT INTO answers (discord_id, guild_id, question_id, user_answer, score, answered_at) VALUES (?, ?, ?, ?, ?, ?)",
+			a.discordID, a.guildID, qID, "test", a.score, now.AddDate(0, 0, -a.daysAgo).Format(timeLayout),
+		)
+		if err != nil {
+			t.Fatalf("Failed to insert answer: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		period    LeaderboardPeriod
+		metric    LeaderboardMetric
+		wantIDs   []string
+		wantTotal int
+	}{
+		{"weekly average", PeriodWeek, MetricAverage, []string{"bob", "alice", "carol"}, 3},
+		{"monthly answers", PeriodMonth, MetricAnswers, []string{"alice", "carol", "bob"}, 3},
+		{"all-time average", PeriodAll, MetricAverage, []string{"bob", "dave", "alice", "carol"}, 4},
+		{"weekly streak", PeriodWeek, MetricStreak, []string{"alice", "bob", "carol"}, 3},
+		{"all-time streak leaves out broken streaks", PeriodAll, MetricStreak, []string{"alice", "carol", "bob"}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, total, err := db.GetLeaderboard("guild-1", tt.period, tt.metric, 10, 0)
+			if err != nil {
+				t.Fatalf("Failed to get leaderboard: %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("Expected total %d, got %d", tt.wantTotal, total)
+			}
+			if len(entries) != len(tt.wantIDs) {
+				t.Fatalf("Expected %d entries, got %+v", len(tt.wantIDs), entries)
+			}
+			for i, id := range tt.wantIDs {
+				if entries[i].DiscordID != id || entries[i].Rank != i+1 {
+					t.Errorf("Expected #%d %s, got #%d %s", i+1, id, entries[i].Rank, entries[i].DiscordID)
+				}
+			}
+		})
+	}
+
+	// Paging continues the ranking
+	entries, _, err := db.GetLeaderboard("guild-1", PeriodAll, MetricAverage, 2, 2)
+	if err != nil {
+		t.Fatalf("Failed to get leaderboard page: %v", err)
+	}
+	if len(entries) != 2 || entries[0].DiscordID != "alice" || entries[0].Rank != 3 {
+		t.Errorf("Expected page starting with #3 alice, got %+v", entries)
+	}
+}
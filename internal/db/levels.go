@@ -0,0 +1,157 @@
+package db
+
+import (
+	"slices"
+	"time"
+)
+
+// DifficultyAuto is the difficulty of users whose level follows their scores
+const DifficultyAuto = "auto"
+
+// CEFRLevels are the levels of automatic difficulty, easiest first
+var CEFRLevels = []string{"A1", "A2", "B1", "B2", "C1", "C2"}
+
+// Automatic difficulty moves a user's level once their last LevelWindow
+// answers at that level average at least LevelUpScore or below LevelDownScore
+const (
+	LevelWindow    = 5
+	LevelUpScore   = 85
+	LevelDownScore = 50
+)
+
+// LevelChange is one change of a user's level. PreviousLevel is empty when
+// automatic difficulty was first turned on.
+type LevelChange struct {
+	DiscordID     string
+	GuildID       string
+	Level         string
+	PreviousLevel string
+	AverageScore  float64
+	ChangedAt     time.Time
+}
+
+// InitialLevel returns the CEFR level a user with the given difficulty starts
+// automatic difficulty at
+func InitialLevel(difficulty string) string {
+	switch difficulty {
+	case "beginner":
+		return "A2"
+	case "advanced":
+		return "C1"
+	default:
+		return "B1"
+	}
+}
+
+// QuestionDifficulty returns the difficulty questions for the user are
+// generated at: their CEFR level in automatic mode, their difficulty otherwise
+func (u *User) QuestionDifficulty() string {
+	if u.Difficulty == DifficultyAuto && u.Level != "" {
+		return u.Level
+	}
+	return u.Difficulty
+}
+
+// SetUserLevel sets a user's CEFR level in a guild and records the change in
+// their level history
+func (db *DB) SetUserLevel(discordID, guildID, level string, averageScore float64) error {
+	var previous string
+	row := db.conn.QueryRow("SELECT level FROM users WHERE discord_id = ? AND guild_id = ?", discordID, guildID)
+	if err := row.Scan(&previous); err != nil {
+		return err
+	}
+
+	if _, err := db.conn.Exec(
+		"UPDATE users SET level = ? WHERE discord_id = ? AND guild_id = ?",
+		level, discordID, guildID,
+	); err != nil {
+		return err
+	}
+
+	_, err := db.conn.Exec(
+		"INSERT INTO level_history (discord_id, guild_id, level, previous_level, average_score) VALUES (?, ?, ?, ?, ?)",
+		discordID, guildID, level, previous, averageScore,
+	)
+	return err
+}
+
+// AdjustLevel moves the level of a user in automatic mode one step up or down
+// if their recent answers at that level call for it. Vocabulary quizzes and
+// answers given after revealing the model answer are not counted. It returns
+// the change, or nil if the level stayed.
+func (db *DB) AdjustLevel(discordID, guildID string) (*LevelChange, error) {
+	user, err := db.GetOrCreateUser(discordID, guildID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Difficulty != DifficultyAuto || user.Level == "" {
+		return nil, nil
+	}
+
+	var count int
+	var average float64
+	row := db.conn.QueryRow(`
+		SELECT COUNT(*), COALESCE(AVG(score), 0) FROM (
+			SELECT a.score FROM answers AS a
+			JOIN questions AS q ON q.id = a.question_id
+			WHERE a.discord_id = ? AND a.guild_id = ? AND a.revealed = 0
+				AND q.difficulty = ? AND q.exercise_type != 'vocab'
+			ORDER BY a.id DESC
+			LIMIT ?
+		)
+	`, discordID, guildID, user.Level, LevelWindow)
+	if err := row.Scan(&count, &average); err != nil {
+		return nil, err
+	}
+	if count < LevelWindow {
+		return nil, nil
+	}
+
+	index := slices.Index(CEFRLevels, user.Level)
+	switch {
+	case average >= LevelUpScore && index >= 0 && index < len(CEFRLevels)-1:
+		index++
+	case average < LevelDownScore && index > 0:
+		index--
+	default:
+		return nil, nil
+	}
+
+	change := &LevelChange{
+		DiscordID:     discordID,
+		GuildID:       guildID,
+		Level:         CEFRLevels[index],
+		PreviousLevel: user.Level,
+		AverageScore:  average,
+		ChangedAt:     time.Now(),
+	}
+	if err := db.SetUserLevel(discordID, guildID, change.Level, average); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// GetLevelHistory returns a user's most recent level changes in a guild, newest first
+func (db *DB) GetLevelHistory(discordID, guildID string, limit int) ([]LevelChange, error) {
+	rows, err := db.conn.Query(`
+		SELECT level, previous_level, average_score, changed_at
+		FROM level_history
+		WHERE discord_id = ? AND guild_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, discordID, guildID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []LevelChange
+	for rows.Next() {
+		c := LevelChange{DiscordID: discordID, GuildID: guildID}
+		if err := rows.Scan(&c.Level, &c.PreviousLevel, &c.AverageScore, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,89 @@
+package db
+
+import "testing"
+
+// answerAtLevel saves an answer with the given score to a new question at the level
+func answerAtLevel(t *testing.T, db *DB, discordID, level string, score int) {
+	t.Helper()
+	qID, err := db.SaveQuestion("guild-1", "問題", level, "テスト", DirectionJaEn)
+	if err != nil {
+		t.Fatalf("Failed to save question: %v", err)
+	}
+	if _, err := db.SaveAnswer(discordID, "guild-1", qID, "a", "a", score, ""); err != nil {
+		t.Fatalf("Failed to save answer: %v", err)
+	}
+}
+
+func TestAdjustLevel(t *testing.T) {
+	db := newTestDB(t)
+
+	user, _ := db.GetOrCreateUser("12345", "guild-1")
+	if user.QuestionDifficulty() != "intermediate" {
+		t.Errorf("Expected the manual difficulty, got %q", user.QuestionDifficulty())
+	}
+
+	// Users not in automatic mode keep their difficulty
+	for range LevelWindow {
+		answerAtLevel(t, db, "12345", "intermediate", 100)
+	}
+	if change, err := db.AdjustLevel("12345", "guild-1"); err != nil || change != nil {
+		t.Fatalf("Expected no change in manual mode, got %+v (%v)", change, err)
+	}
+
+	db.UpdateUserSettings("12345", "guild-1", DifficultyAuto, user.Theme)
+	if err := db.SetUserLevel("12345", "guild-1", InitialLevel(user.Difficulty), 0); err != nil {
+		t.Fatalf("Failed to set level: %v", err)
+	}
+	user, _ = db.GetOrCreateUser("12345", "guild-1")
+	if user.Level != "B1" || user.QuestionDifficulty() != "B1" {
+		t.Fatalf("Expected to start at B1, got %+v", user)
+	}
+
+	// Answers at other levels and a window that is not yet full do not count
+	for range LevelWindow - 1 {
+		answerAtLevel(t, db, "12345", "B1", 90)
+	}
+	if change, _ := db.AdjustLevel("12345", "guild-1"); change != nil {
+		t.Fatalf("Expected no change before the window is full, got %+v", change)
+	}
+
+	answerAtLevel(t, db, "12345", "B1", 80)
+	change, err := db.AdjustLevel("12345", "guild-1")
+	if err != nil || change == nil || change.PreviousLevel != "B1" || change.Level != "B2" || change.AverageScore != 88 {
+		t.Fatalf("Expected a move up to B2, got %+v (%v)", change, err)
+	}
+
+	// The window starts over at the new level
+	if change, _ := db.AdjustLevel("12345", "guild-1"); change != nil {
+		t.Fatalf("Expected no change right after moving, got %+v", change)
+	}
+	for range LevelWindow {
+		answerAtLevel(t, db, "12345", "B2", 30)
+	}
+	if change, _ := db.AdjustLevel("12345", "guild-1"); change == nil || change.Level != "B1" {
+		t.Fatalf("Expected a move back down to B1, got %+v", change)
+	}
+
+	history, err := db.GetLevelHistory("12345", "guild-1", 10)
+	if err != nil {
+		t.Fatalf("Failed to get level history: %v", err)
+	}
+	if len(history) != 3 || history[0].Level != "B1" || history[1].Level != "B2" || history[2].PreviousLevel != "" {
+		t.Errorf("Unexpected level history: %+v", history)
+	}
+}
+
+func TestAdjustLevel_Bounds(t *testing.T) {
+	db := newTestDB(t)
+
+	db.GetOrCreateUser("12345", "guild-1")
+	db.UpdateUserSettings("12345", "guild-1", DifficultyAuto, "日常会話")
+	db.SetUserLevel("12345", "guild-1", "C2", 0)
+
+	for range LevelWindow {
+		answerAtLevel(t, db, "12345", "C2", 100)
+	}
+	if change, _ := db.AdjustLevel("12345", "guild-1"); change != nil {
+		t.Errorf("Expected C2 to be the highest level, got %+v", change)
+	}
+}
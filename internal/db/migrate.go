@@ -0,0 +1,205 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is a numbered schema change loaded from migrations/NNNN_name.sql
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// loadMigrations reads the embedded migrations sorted by version
+func loadMigrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		filename := entry.Name()
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(filename, ".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration filename %q", filename)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q", filename)
+		}
+
+		content, err := migrationFiles.ReadFile(path.Join("migrations", filename))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// ensureVersionTable creates the schema_version table if needed
+func (db *DB) ensureVersionTable() error {
+	_, err := db.conn.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+// MigrationStatus lists every known migration and whether it has been applied
+func (db *DB) MigrationStatus() ([]MigrationStatus, error) {
+	if err := db.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]time.Time)
+	rows, err := db.conn.Query("SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		statuses[i] = MigrationStatus{Migration: m, Applied: ok, AppliedAt: appliedAt}
+	}
+	return statuses, nil
+}
+
+// Migrate applies all pending migrations in order, each in its own
+// transaction, and returns how many were applied
+func (db *DB) Migrate() (int, error) {
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, s := range statuses {
+		if s.Applied {
+			continue
+		}
+		if err := db.applyMigration(s.Migration); err != nil {
+			return count, fmt.Errorf("migration %04d_%s: %w", s.Version, s.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// applyMigration runs a single migration and records it
+func (db *DB) applyMigration(m Migration) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return err
+	}
+
+	if m.Version == 1 {
+		if err := adoptLegacyColumns(tx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_version (version, name) VALUES (?, ?)", m.Version, m.Name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// adoptLegacyColumns adds columns that databases created before migrations
+// existed may lack, since the initial migration's CREATE TABLE IF NOT EXISTS
+// leaves their existing tables untouched
+func adoptLegacyColumns(tx *sql.Tx) error {
+	columns := []struct{ table, column, definition string }{
+		{"users", "timezone", "TEXT DEFAULT 'Asia/Tokyo'"},
+		{"users", "schedule", "TEXT DEFAULT ''"},
+		{"users", "delivery", "TEXT DEFAULT 'channel'"},
+	}
+	for _, c := range columns {
+		exists, err := columnExists(tx, c.table, c.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnExists reports whether a table has the given column
+func columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, colType    string
+			defaultValue     sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected at least one migration")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("Expected migration %d to have version %d, got %d", i, i+1, m.Version)
+		}
+		if m.Name == "" || m.SQL == "" {
+			t.Errorf("Migration %d is missing name or SQL", m.Version)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	for _, s := range statuses {
+		if s.Applied {
+			t.Errorf("Expected migration %d to be pending", s.Version)
+		}
+	}
+
+	applied, err := db.Migrate()
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if applied != len(statuses) {
+		t.Errorf("Expected %d migrations applied, got %d", len(statuses), applied)
+	}
+
+	// Running again is a no-op
+	applied, err = db.Migrate()
+	if err != nil {
+		t.Fatalf("Failed to migrate again: %v", err)
+	}
+	if applied != 0 {
+		t.Errorf("Expected no migrations on second run, got %d", applied)
+	}
+
+	statuses, _ = db.MigrationStatus()
+	for _, s := range statuses {
+		if !s.Applied || s.AppliedAt.IsZero() {
+			t.Errorf("Expected migration %d to be applied", s.Version)
+		}
+	}
+}
+
+func TestMigrate_AdoptsLegacyDatabase(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// Schema as created by the original initTables, with existing data
+	_, err = db.conn.Exec(`
+	CREATE TABLE users (
+		discord_id TEXT PRIMARY KEY,
+		difficulty TEXT DEFAULT 'intermediate',
+		theme TEXT DEFAULT '日常会話',
+		schedule_enabled INTEGER DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE questions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		japanese TEXT NOT NULL,
+		difficulty TEXT NOT NULL,
+		theme TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE answers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		discord_id TEXT NOT NULL,
+		question_id INTEGER NOT NULL,
+		user_answer TEXT NOT NULL,
+		model_answer TEXT,
+		score INTEGER,
+		feedback TEXT,
+		answered_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	INSERT INTO users (discord_id, difficulty) VALUES ('12345', 'advanced');
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate legacy database: %v", err)
+	}
+
+	user, err := db.GetOrCreateUser("12345", "")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if user.Difficulty != "advanced" {
+		t.Errorf("Expected existing data to be kept, got difficulty '%s'", user.Difficulty)
+	}
+	if user.Timezone != "Asia/Tokyo" || user.Delivery != DeliveryChannel {
+		t.Errorf("Expected new columns with defaults, got %q %q", user.Timezone, user.Delivery)
+	}
+}
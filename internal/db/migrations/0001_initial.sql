@@ -0,0 +1,53 @@
+-- Initial schema. Statements use IF NOT EXISTS so databases created before
+-- migrations existed can be adopted.
+
+CREATE TABLE IF NOT EXISTS users (
+    discord_id TEXT PRIMARY KEY,
+    difficulty TEXT DEFAULT 'intermediate',
+    theme TEXT DEFAULT '日常会話',
+    schedule_enabled INTEGER DEFAULT 1,
+    timezone TEXT DEFAULT 'Asia/Tokyo',
+    schedule TEXT DEFAULT '',
+    delivery TEXT DEFAULT 'channel',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS questions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    japanese TEXT NOT NULL,
+    difficulty TEXT NOT NULL,
+    theme TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS answers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    discord_id TEXT NOT NULL,
+    question_id INTEGER NOT NULL,
+    user_answer TEXT NOT NULL,
+    model_answer TEXT,
+    score INTEGER,
+    feedback TEXT,
+    answered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (question_id) REFERENCES questions(id)
+);
+
+CREATE TABLE IF NOT EXISTS reviews (
+    discord_id TEXT NOT NULL,
+    question_id INTEGER NOT NULL,
+    ease_factor REAL NOT NULL DEFAULT 2.5,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    due_at DATETIME NOT NULL,
+    PRIMARY KEY (discord_id, question_id),
+    FOREIGN KEY (question_id) REFERENCES questions(id)
+);
+
+CREATE TABLE IF NOT EXISTS quiz_messages (
+    message_id TEXT PRIMARY KEY,
+    channel_id TEXT NOT NULL,
+    question_id INTEGER NOT NULL,
+    discord_id TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (question_id) REFERENCES questions(id)
+);
@@ -0,0 +1,12 @@
+-- Per-guild configuration for servers the bot is set up in.
+
+CREATE TABLE guilds (
+    guild_id TEXT PRIMARY KEY,
+    channel_id TEXT NOT NULL DEFAULT '',
+    theme TEXT NOT NULL DEFAULT '日常会話',
+    difficulty TEXT NOT NULL DEFAULT 'intermediate',
+    schedule_enabled INTEGER NOT NULL DEFAULT 0,
+    schedule TEXT NOT NULL DEFAULT '',
+    timezone TEXT NOT NULL DEFAULT 'Asia/Tokyo',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
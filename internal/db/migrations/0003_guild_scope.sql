@@ -0,0 +1,27 @@
+-- Scope user settings and progress per guild. guild_id is empty for DMs;
+-- rows that existed before this migration keep the empty guild_id, so their
+-- progress remains visible in global stats.
+
+CREATE TABLE users_scoped (
+    discord_id TEXT NOT NULL,
+    guild_id TEXT NOT NULL DEFAULT '',
+    difficulty TEXT DEFAULT 'intermediate',
+    theme TEXT DEFAULT '日常会話',
+    schedule_enabled INTEGER DEFAULT 1,
+    timezone TEXT DEFAULT 'Asia/Tokyo',
+    schedule TEXT DEFAULT '',
+    delivery TEXT DEFAULT 'channel',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (discord_id, guild_id)
+);
+
+INSERT INTO users_scoped (discord_id, difficulty, theme, schedule_enabled, timezone, schedule, delivery, created_at)
+SELECT discord_id, difficulty, theme, schedule_enabled, timezone, schedule, delivery, created_at FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_scoped RENAME TO users;
+
+ALTER TABLE questions ADD COLUMN guild_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE answers ADD COLUMN guild_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_answers_user_guild ON answers (discord_id, guild_id);
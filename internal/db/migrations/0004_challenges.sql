@@ -0,0 +1,24 @@
+-- Group challenges: one question posted to a channel, replies collected until
+-- ends_at, then evaluated together.
+
+CREATE TABLE challenges (
+    message_id TEXT PRIMARY KEY,
+    guild_id TEXT NOT NULL,
+    channel_id TEXT NOT NULL,
+    question_id INTEGER NOT NULL,
+    ends_at DATETIME NOT NULL,
+    closed INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (question_id) REFERENCES questions(id)
+);
+
+CREATE TABLE challenge_entries (
+    message_id TEXT NOT NULL,
+    discord_id TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    submitted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, discord_id)
+);
+
+-- Minutes a scheduled guild quiz stays open as a challenge; 0 posts a normal quiz.
+ALTER TABLE guilds ADD COLUMN challenge_minutes INTEGER NOT NULL DEFAULT 0;
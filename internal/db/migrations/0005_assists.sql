@@ -0,0 +1,21 @@
+-- Hints and revealed answers. Answers given after a hint are penalised;
+-- answers given after revealing the model answer are kept for practice but
+-- are not counted as attempts in stats or leaderboards.
+
+CREATE TABLE assists (
+    discord_id TEXT NOT NULL,
+    question_id INTEGER NOT NULL,
+    guild_id TEXT NOT NULL DEFAULT '',
+    hint_used INTEGER NOT NULL DEFAULT 0,
+    revealed INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (discord_id, question_id),
+    FOREIGN KEY (question_id) REFERENCES questions(id)
+);
+
+-- Generated hints and model answers are cached so every learner sees the same ones.
+ALTER TABLE questions ADD COLUMN hint TEXT NOT NULL DEFAULT '';
+ALTER TABLE questions ADD COLUMN model_answer TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE answers ADD COLUMN hint_used INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE answers ADD COLUMN revealed INTEGER NOT NULL DEFAULT 0;
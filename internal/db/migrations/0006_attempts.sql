@@ -0,0 +1,13 @@
+-- Number each user's answers to a question so retries can be compared.
+-- Existing answers are numbered in the order they were given.
+
+ALTER TABLE answers ADD COLUMN attempt INTEGER NOT NULL DEFAULT 1;
+
+UPDATE answers SET attempt = (
+    SELECT COUNT(*) FROM answers AS earlier
+    WHERE earlier.discord_id = answers.discord_id
+      AND earlier.question_id = answers.question_id
+      AND earlier.id <= answers.id
+);
+
+CREATE INDEX idx_answers_user_question ON answers (discord_id, question_id);
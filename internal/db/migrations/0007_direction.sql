@@ -0,0 +1,6 @@
+-- Translation direction: users choose ja-en, en-ja or mixed, and each question
+-- records the direction it was asked in. For en-ja questions the japanese
+-- column holds the English sentence shown to the learner.
+
+ALTER TABLE users ADD COLUMN direction TEXT NOT NULL DEFAULT 'ja-en';
+ALTER TABLE questions ADD COLUMN direction TEXT NOT NULL DEFAULT 'ja-en';
@@ -0,0 +1,7 @@
+-- Exercise types beyond free translation. For these the japanese column holds
+-- the exercise prompt, context the Japanese meaning of the target sentence and
+-- choices the newline-separated options of multiple choice exercises.
+
+ALTER TABLE questions ADD COLUMN exercise_type TEXT NOT NULL DEFAULT 'translation';
+ALTER TABLE questions ADD COLUMN context TEXT NOT NULL DEFAULT '';
+ALTER TABLE questions ADD COLUMN choices TEXT NOT NULL DEFAULT '';
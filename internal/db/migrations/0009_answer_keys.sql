@@ -0,0 +1,5 @@
+-- Answer keys of closed exercises so they can be graded without the model.
+-- alternates holds the newline-separated other accepted answers.
+
+ALTER TABLE questions ADD COLUMN answer_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE questions ADD COLUMN alternates TEXT NOT NULL DEFAULT '';
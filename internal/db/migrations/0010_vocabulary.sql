@@ -0,0 +1,25 @@
+-- Personal vocabulary notebooks. Entries come from vocabulary corrections in
+-- evaluations, key phrases of model answers or the user's own additions.
+
+CREATE TABLE vocabulary (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    discord_id TEXT NOT NULL,
+    phrase TEXT NOT NULL COLLATE NOCASE,
+    meaning TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL,
+    question_id INTEGER,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (discord_id, phrase),
+    FOREIGN KEY (question_id) REFERENCES questions(id)
+);
+
+-- Key phrases of each question's model answer, cached from the first
+-- evaluation that named them so every learner saves the same ones.
+CREATE TABLE key_phrases (
+    question_id INTEGER NOT NULL,
+    phrase TEXT NOT NULL,
+    meaning TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (question_id, phrase),
+    FOREIGN KEY (question_id) REFERENCES questions(id)
+);
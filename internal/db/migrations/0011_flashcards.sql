@@ -0,0 +1,8 @@
+-- Flashcard schedule of vocabulary entries, using the same SM-2 fields as
+-- reviews. due_at is NULL until an entry is first drilled, so new entries are
+-- due straight away.
+
+ALTER TABLE vocabulary ADD COLUMN ease_factor REAL NOT NULL DEFAULT 2.5;
+ALTER TABLE vocabulary ADD COLUMN interval_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vocabulary ADD COLUMN repetitions INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vocabulary ADD COLUMN due_at DATETIME;
@@ -0,0 +1,13 @@
+-- Individual errors found in each answer, classified by the evaluation's
+-- correction category, for weakness analytics.
+
+CREATE TABLE answer_errors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    answer_id INTEGER NOT NULL,
+    category TEXT NOT NULL,
+    span TEXT NOT NULL DEFAULT '',
+    suggestion TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (answer_id) REFERENCES answers(id)
+);
+
+CREATE INDEX idx_answer_errors_answer ON answer_errors (answer_id);
@@ -0,0 +1,16 @@
+-- Automatic difficulty. Users whose difficulty is 'auto' are asked questions
+-- at their CEFR level, which moves with their recent scores.
+
+ALTER TABLE users ADD COLUMN level TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE level_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    discord_id TEXT NOT NULL,
+    guild_id TEXT NOT NULL DEFAULT '',
+    level TEXT NOT NULL,
+    previous_level TEXT NOT NULL DEFAULT '',
+    average_score REAL NOT NULL DEFAULT 0,
+    changed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_level_history_user ON level_history (discord_id, guild_id);
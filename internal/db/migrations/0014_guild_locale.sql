@@ -0,0 +1,4 @@
+-- Language the model writes feedback, explanations and hints in for a server:
+-- 'ja' or 'en'.
+
+ALTER TABLE guilds ADD COLUMN locale TEXT NOT NULL DEFAULT 'ja';
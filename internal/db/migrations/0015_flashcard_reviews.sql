@@ -0,0 +1,14 @@
+-- History of flashcard ratings. The schedule on vocabulary only keeps the
+-- latest state, so each "knew it / didn't" is recorded here as well.
+
+CREATE TABLE flashcard_reviews (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    discord_id TEXT NOT NULL,
+    vocabulary_id INTEGER NOT NULL,
+    knew INTEGER NOT NULL,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    reviewed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (vocabulary_id) REFERENCES vocabulary(id)
+);
+
+CREATE INDEX idx_flashcard_reviews_user ON flashcard_reviews (discord_id, vocabulary_id);
@@ -0,0 +1,8 @@
+-- Help that still applies to a user's next answer. hint_used and revealed
+-- keep the history shown in /stats; the pending flags are cleared when
+-- /review serves the question again as a fresh attempt.
+
+ALTER TABLE assists ADD COLUMN hint_pending INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE assists ADD COLUMN reveal_pending INTEGER NOT NULL DEFAULT 0;
+
+UPDATE assists SET hint_pending = hint_used, reveal_pending = revealed;
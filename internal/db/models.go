@@ -1,60 +1,111 @@
 package db
 
-import "time"
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
 
-// User represents a Discord user's settings
+// Quiz delivery destinations
+const (
+	DeliveryChannel = "channel"
+	DeliveryDM      = "dm"
+)
+
+// Translation directions. Users may choose DirectionMixed; questions are
+// always asked in one of the other two.
+const (
+	DirectionJaEn  = "ja-en"
+	DirectionEnJa  = "en-ja"
+	DirectionMixed = "mixed"
+)
+
+// ExerciseTranslation is the exercise type of free translation questions
+const ExerciseTranslation = "translation"
+
+// User represents a Discord user's settings in one guild. GuildID is empty
+// for settings used in DMs.
 type User struct {
 	DiscordID       string
+	GuildID         string
 	Difficulty      string
 	Theme           string
 	ScheduleEnabled bool
+	Timezone        string
+	Schedule        string
+	Delivery        string
+	Direction       string
+	Level           string // CEFR level used when Difficulty is DifficultyAuto
 	CreatedAt       time.Time
 }
 
 // Question represents a quiz question
 type Question struct {
-	ID         int64
-	Japanese   string
-	Difficulty string
-	Theme      string
-	CreatedAt  time.Time
+	ID           int64
+	GuildID      string
+	Japanese     string // sentence shown to the learner; English for en-ja questions and the prompt of other exercises
+	Difficulty   string
+	Theme        string
+	Direction    string
+	ExerciseType string
+	Context      string   // Japanese meaning of the target sentence of non-translation exercises
+	Choices      []string // options of multiple choice exercises
+	AnswerKey    string   // canonical answer of non-translation exercises
+	Alternates   []string // other accepted answers of non-translation exercises
+	Hint         string   // cached hint, empty until someone asks for one
+	ModelAnswer  string   // cached model answer, empty until someone reveals it
+	CreatedAt    time.Time
 }
 
 // Answer represents a user's answer to a question
 type Answer struct {
 	ID          int64
 	DiscordID   string
+	GuildID     string
 	QuestionID  int64
 	UserAnswer  string
 	ModelAnswer string
 	Score       int
 	Feedback    string
+	HintUsed    bool
+	Revealed    bool
+	Attempt     int
 	AnsweredAt  time.Time
 }
 
-// GetOrCreateUser gets a user by Discord ID, creating if not exists
-func (db *DB) GetOrCreateUser(discordID string) (*User, error) {
-	user := &User{DiscordID: discordID}
+// GetOrCreateUser gets a user's settings in a guild, creating them if they do
+// not exist. New users start with the guild's defaults and with scheduled
+// quizzes off until they turn them on; guildID is empty for DMs.
+func (db *DB) GetOrCreateUser(discordID, guildID string) (*User, error) {
+	user := &User{DiscordID: discordID, GuildID: guildID}
 
 	row := db.conn.QueryRow(
-		"SELECT difficulty, theme, schedule_enabled, created_at FROM users WHERE discord_id = ?",
-		discordID,
+		"SELECT difficulty, theme, schedule_enabled, timezone, schedule, delivery, direction, level, created_at FROM users WHERE discord_id = ? AND guild_id = ?",
+		discordID, guildID,
 	)
 
 	var scheduleEnabled int
-	err := row.Scan(&user.Difficulty, &user.Theme, &scheduleEnabled, &user.CreatedAt)
+	err := row.Scan(&user.Difficulty, &user.Theme, &scheduleEnabled, &user.Timezone, &user.Schedule, &user.Delivery, &user.Direction, &user.Level, &user.CreatedAt)
 	if err != nil {
 		// User doesn't exist, create new one
+		guild, err := db.GetGuild(guildID)
+		if err != nil {
+			return nil, err
+		}
 		_, err = db.conn.Exec(
-			"INSERT INTO users (discord_id) VALUES (?)",
-			discordID,
+			"INSERT INTO users (discord_id, guild_id, difficulty, theme, schedule_enabled, timezone) VALUES (?, ?, ?, ?, 0, ?)",
+			discordID, guildID, guild.Difficulty, guild.Theme, guild.Timezone,
 		)
 		if err != nil {
 			return nil, err
 		}
-		user.Difficulty = "intermediate"
-		user.Theme = "日常会話"
-		user.ScheduleEnabled = true
+		user.Difficulty = guild.Difficulty
+		user.Theme = guild.Theme
+		user.ScheduleEnabled = false
+		user.Timezone = guild.Timezone
+		user.Schedule = ""
+		user.Delivery = DeliveryChannel
+		user.Direction = DirectionJaEn
 		user.CreatedAt = time.Now()
 	} else {
 		user.ScheduleEnabled = scheduleEnabled == 1
@@ -64,32 +115,96 @@ func (db *DB) GetOrCreateUser(discordID string) (*User, error) {
 }
 
 // UpdateUserSettings updates a user's difficulty and theme
-func (db *DB) UpdateUserSettings(discordID, difficulty, theme string) error {
+func (db *DB) UpdateUserSettings(discordID, guildID, difficulty, theme string) error {
 	_, err := db.conn.Exec(
-		"UPDATE users SET difficulty = ?, theme = ? WHERE discord_id = ?",
-		difficulty, theme, discordID,
+		"UPDATE users SET difficulty = ?, theme = ? WHERE discord_id = ? AND guild_id = ?",
+		difficulty, theme, discordID, guildID,
 	)
 	return err
 }
 
 // UpdateUserSchedule updates a user's schedule setting
-func (db *DB) UpdateUserSchedule(discordID string, enabled bool) error {
+func (db *DB) UpdateUserSchedule(discordID, guildID string, enabled bool) error {
 	enabledInt := 0
 	if enabled {
 		enabledInt = 1
 	}
 	_, err := db.conn.Exec(
-		"UPDATE users SET schedule_enabled = ? WHERE discord_id = ?",
-		enabledInt, discordID,
+		"UPDATE users SET schedule_enabled = ? WHERE discord_id = ? AND guild_id = ?",
+		enabledInt, discordID, guildID,
+	)
+	return err
+}
+
+// UpdateUserDeliveryTimes updates a user's time zone and delivery schedule
+func (db *DB) UpdateUserDeliveryTimes(discordID, guildID, timezone, schedule string) error {
+	_, err := db.conn.Exec(
+		"UPDATE users SET timezone = ?, schedule = ? WHERE discord_id = ? AND guild_id = ?",
+		timezone, schedule, discordID, guildID,
 	)
 	return err
 }
 
-// SaveQuestion saves a new question and returns its ID
-func (db *DB) SaveQuestion(japanese, difficulty, theme string) (int64, error) {
+// UpdateUserDelivery updates where a user's quizzes are delivered
+func (db *DB) UpdateUserDelivery(discordID, guildID, delivery string) error {
+	_, err := db.conn.Exec(
+		"UPDATE users SET delivery = ? WHERE discord_id = ? AND guild_id = ?",
+		delivery, discordID, guildID,
+	)
+	return err
+}
+
+// UpdateUserDirection updates the translation direction of a user's quizzes
+func (db *DB) UpdateUserDirection(discordID, guildID, direction string) error {
+	_, err := db.conn.Exec(
+		"UPDATE users SET direction = ? WHERE discord_id = ? AND guild_id = ?",
+		direction, discordID, guildID,
+	)
+	return err
+}
+
+// GetScheduledUsers returns the settings of every user and guild pair with
+// scheduled quizzes enabled
+func (db *DB) GetScheduledUsers() ([]*User, error) {
+	rows, err := db.conn.Query(
+		"SELECT discord_id, guild_id, difficulty, theme, schedule_enabled, timezone, schedule, delivery, direction, level, created_at FROM users WHERE schedule_enabled = 1",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		user := &User{}
+		var scheduleEnabled int
+		if err := rows.Scan(&user.DiscordID, &user.GuildID, &user.Difficulty, &user.Theme, &scheduleEnabled, &user.Timezone, &user.Schedule, &user.Delivery, &user.Direction, &user.Level, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		user.ScheduleEnabled = scheduleEnabled == 1
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// SaveQuestion saves a new question asked in a guild in the given direction
+// and returns its ID
+func (db *DB) SaveQuestion(guildID, japanese, difficulty, theme, direction string) (int64, error) {
+	result, err := db.conn.Exec(
+		"INSERT INTO questions (guild_id, japanese, difficulty, theme, direction) VALUES (?, ?, ?, ?, ?)",
+		guildID, japanese, difficulty, theme, direction,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// SaveExercise saves a new exercise other than free translation and returns its ID
+func (db *DB) SaveExercise(q *Question) (int64, error) {
 	result, err := db.conn.Exec(
-		"INSERT INTO questions (japanese, difficulty, theme) VALUES (?, ?, ?)",
-		japanese, difficulty, theme,
+		"INSERT INTO questions (guild_id, japanese, difficulty, theme, exercise_type, context, choices, answer_key, alternates) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		q.GuildID, q.Japanese, q.Difficulty, q.Theme, q.ExerciseType, q.Context, strings.Join(q.Choices, "\n"), q.AnswerKey, strings.Join(q.Alternates, "\n"),
 	)
 	if err != nil {
 		return 0, err
@@ -101,57 +216,180 @@ func (db *DB) SaveQuestion(japanese, difficulty, theme string) (int64, error) {
 func (db *DB) GetQuestion(id int64) (*Question, error) {
 	q := &Question{ID: id}
 	row := db.conn.QueryRow(
-		"SELECT japanese, difficulty, theme, created_at FROM questions WHERE id = ?",
+		"SELECT guild_id, japanese, difficulty, theme, direction, exercise_type, context, choices, answer_key, alternates, hint, model_answer, created_at FROM questions WHERE id = ?",
 		id,
 	)
-	err := row.Scan(&q.Japanese, &q.Difficulty, &q.Theme, &q.CreatedAt)
+	var choices, alternates string
+	err := row.Scan(&q.GuildID, &q.Japanese, &q.Difficulty, &q.Theme, &q.Direction, &q.ExerciseType, &q.Context, &choices, &q.AnswerKey, &alternates, &q.Hint, &q.ModelAnswer, &q.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
+	if choices != "" {
+		q.Choices = strings.Split(choices, "\n")
+	}
+	if alternates != "" {
+		q.Alternates = strings.Split(alternates, "\n")
+	}
 	return q, nil
 }
 
-// SaveAnswer saves a user's answer
-func (db *DB) SaveAnswer(discordID string, questionID int64, userAnswer, modelAnswer string, score int, feedback string) error {
-	_, err := db.conn.Exec(
-		"INSERT INTO answers (discord_id, question_id, user_answer, model_answer, score, feedback) VALUES (?, ?, ?, ?, ?, ?)",
-		discordID, questionID, userAnswer, modelAnswer, score, feedback,
-	)
-	return err
+// SaveAnswer saves a user's answer given in a guild as their next attempt at
+// the question, marking whether they took a hint or revealed the model answer
+// to the question beforehand, and returns its ID
+func (db *DB) SaveAnswer(discordID, guildID string, questionID int64, userAnswer, modelAnswer string, score int, feedback string) (int64, error) {
+	result, err := db.conn.Exec(`
+		INSERT INTO answers (discord_id, guild_id, question_id, user_answer, model_answer, score, feedback, hint_used, revealed, attempt)
+		SELECT ?, ?, ?, ?, ?, ?, ?,
+			COALESCE((SELECT hint_pending FROM assists WHERE discord_id = ? AND question_id = ?), 0),
+			COALESCE((SELECT reveal_pending FROM assists WHERE discord_id = ? AND question_id = ?), 0),
+			(SELECT COUNT(*) + 1 FROM answers WHERE discord_id = ? AND question_id = ?)
+	`, discordID, guildID, questionID, userAnswer, modelAnswer, score, feedback,
+		discordID, questionID, discordID, questionID, discordID, questionID)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetLastAnswer returns a user's most recent answer to a question, or nil if
+// they have not answered it yet
+func (db *DB) GetLastAnswer(discordID string, questionID int64) (*Answer, error) {
+	a := &Answer{DiscordID: discordID, QuestionID: questionID}
+	row := db.conn.QueryRow(`
+		SELECT id, guild_id, user_answer, COALESCE(model_answer, ''), COALESCE(score, 0), COALESCE(feedback, ''), hint_used, revealed, attempt, answered_at
+		FROM answers
+		WHERE discord_id = ? AND question_id = ?
+		ORDER BY attempt DESC, id DESC
+		LIMIT 1
+	`, discordID, questionID)
+
+	var hintUsed, revealed int
+	err := row.Scan(&a.ID, &a.GuildID, &a.UserAnswer, &a.ModelAnswer, &a.Score, &a.Feedback, &hintUsed, &revealed, &a.Attempt, &a.AnsweredAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	a.HintUsed = hintUsed == 1
+	a.Revealed = revealed == 1
+	return a, nil
 }
 
+// StatsScope selects which answers GetUserStats counts
+type StatsScope string
+
+// Stats scopes
+const (
+	ScopeServer StatsScope = "server"
+	ScopeGlobal StatsScope = "global"
+)
+
 // UserStats represents a user's learning statistics
 type UserStats struct {
-	TotalAnswers   int
-	AverageScore   float64
-	HighestScore   int
-	AnswersToday   int
-	CurrentStreak  int
+	TotalAnswers  int
+	AverageScore  float64
+	HighestScore  int
+	AnswersToday  int
+	CurrentStreak int
+	LongestStreak int
+	HintsUsed     int
+	Revealed      int
+	Improved      int // questions whose latest attempt beat the first
 }
 
-// GetUserStats gets statistics for a user
-func (db *DB) GetUserStats(discordID string) (*UserStats, error) {
+// GetUserStats gets statistics for a user, either from answers given in the
+// guild or across all guilds and DMs. Days are counted in the time zone of
+// the user's settings for the guild. Answers given after revealing the model
+// answer are not counted as attempts.
+func (db *DB) GetUserStats(discordID, guildID string, scope StatsScope) (*UserStats, error) {
 	stats := &UserStats{}
 
+	where := "discord_id = ? AND guild_id = ?"
+	args := []any{discordID, guildID}
+	if scope == ScopeGlobal {
+		where = "discord_id = ?"
+		args = args[:1]
+	}
+
 	// Total answers and average score
 	row := db.conn.QueryRow(`
 		SELECT COUNT(*), COALESCE(AVG(score), 0), COALESCE(MAX(score), 0)
-		FROM answers WHERE discord_id = ?
-	`, discordID)
+		FROM answers WHERE revealed = 0 AND `+where, args...)
 	err := row.Scan(&stats.TotalAnswers, &stats.AverageScore, &stats.HighestScore)
 	if err != nil {
 		return nil, err
 	}
 
-	// Answers today
+	// Questions retried with a better score than the first attempt
 	row = db.conn.QueryRow(`
-		SELECT COUNT(*) FROM answers
-		WHERE discord_id = ? AND DATE(answered_at) = DATE('now')
-	`, discordID)
-	err = row.Scan(&stats.AnswersToday)
+		SELECT COUNT(*) FROM (
+			SELECT MIN(id) AS first_id, MAX(id) AS last_id
+			FROM answers WHERE revealed = 0 AND `+where+`
+			GROUP BY discord_id, question_id
+			HAVING COUNT(*) > 1
+		) AS retried
+		JOIN answers AS first ON first.id = retried.first_id
+		JOIN answers AS last ON last.id = retried.last_id
+		WHERE last.score > first.score`, args...)
+	if err := row.Scan(&stats.Improved); err != nil {
+		return nil, err
+	}
+
+	// Hints taken and answers revealed
+	row = db.conn.QueryRow(`
+		SELECT COALESCE(SUM(hint_used), 0), COALESCE(SUM(revealed), 0)
+		FROM assists WHERE `+where, args...)
+	if err := row.Scan(&stats.HintsUsed, &stats.Revealed); err != nil {
+		return nil, err
+	}
+
+	loc, err := db.userLocation(discordID, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Answer days for today's count and streaks
+	rows, err := db.conn.Query("SELECT answered_at FROM answers WHERE revealed = 0 AND "+where, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	now := time.Now()
+	today := localDay(now, loc)
+	var answeredAt []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		answeredAt = append(answeredAt, t)
+		if localDay(t, loc).Equal(today) {
+			stats.AnswersToday++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats.CurrentStreak, stats.LongestStreak = computeStreaks(answeredAt, now, loc)
 
 	return stats, nil
 }
+
+// userLocation returns the user's configured time zone in a guild, defaulting to Asia/Tokyo
+func (db *DB) userLocation(discordID, guildID string) (*time.Location, error) {
+	timezone := "Asia/Tokyo"
+	row := db.conn.QueryRow("SELECT timezone FROM users WHERE discord_id = ? AND guild_id = ?", discordID, guildID)
+	err := row.Scan(&timezone)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC, nil
+	}
+	return loc, nil
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// QuizMessage links a Discord message to the question it carries
+type QuizMessage struct {
+	MessageID  string
+	ChannelID  string
+	QuestionID int64
+	DiscordID  string // intended recipient; empty means anyone may answer
+	CreatedAt  time.Time
+}
+
+// SaveQuizMessage records which question a sent message carries and who it is for
+func (db *DB) SaveQuizMessage(messageID, channelID string, questionID int64, discordID string) error {
+	_, err := db.conn.Exec(
+		"INSERT OR REPLACE INTO quiz_messages (message_id, channel_id, question_id, discord_id) VALUES (?, ?, ?, ?)",
+		messageID, channelID, questionID, discordID,
+	)
+	return err
+}
+
+// GetQuizMessage looks up a quiz message by Discord message ID, returning nil if it is not a quiz
+func (db *DB) GetQuizMessage(messageID string) (*QuizMessage, error) {
+	qm := &QuizMessage{MessageID: messageID}
+	row := db.conn.QueryRow(
+		"SELECT channel_id, question_id, discord_id, created_at FROM quiz_messages WHERE message_id = ?",
+		messageID,
+	)
+	err := row.Scan(&qm.ChannelID, &qm.QuestionID, &qm.DiscordID, &qm.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return qm, nil
+}
@@ -0,0 +1,31 @@
+package db
+
+import "testing"
+
+func TestQuizMessages(t *testing.T) {
+	db := newTestDB(t)
+
+	qID, _ := db.SaveQuestion("", "テスト問題", "beginner", "テスト", DirectionJaEn)
+	if err := db.SaveQuizMessage("msg-1", "channel-1", qID, "12345"); err != nil {
+		t.Fatalf("Failed to save quiz message: %v", err)
+	}
+
+	qm, err := db.GetQuizMessage("msg-1")
+	if err != nil {
+		t.Fatalf("Failed to get quiz message: %v", err)
+	}
+	if qm == nil {
+		t.Fatal("Expected quiz message, got nil")
+	}
+	if qm.QuestionID != qID || qm.ChannelID != "channel-1" || qm.DiscordID != "12345" {
+		t.Errorf("Unexpected quiz message: %+v", qm)
+	}
+
+	qm, err = db.GetQuizMessage("unknown")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if qm != nil {
+		t.Errorf("Expected nil for unknown message, got %+v", qm)
+	}
+}
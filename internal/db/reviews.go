@@ -0,0 +1,123 @@
+package db
+
+import (
+	"database/sql"
+	"math"
+	"time"
+)
+
+// ReviewThreshold is the score below which a question enters the review queue
+const ReviewThreshold = 70
+
+// Review represents a spaced-repetition schedule for a question a user got wrong
+type Review struct {
+	DiscordID   string
+	QuestionID  int64
+	EaseFactor  float64
+	Interval    int
+	Repetitions int
+	DueAt       time.Time
+}
+
+// RecordReview updates the review schedule for a question after it was answered.
+// Questions answered below ReviewThreshold are added to the queue; questions
+// already in the queue are rescheduled with the SM-2 algorithm.
+func (db *DB) RecordReview(discordID string, questionID int64, score int) error {
+	r := &Review{DiscordID: discordID, QuestionID: questionID}
+
+	row := db.conn.QueryRow(
+		"SELECT ease_factor, interval_days, repetitions FROM reviews WHERE discord_id = ? AND question_id = ?",
+		discordID, questionID,
+	)
+	err := row.Scan(&r.EaseFactor, &r.Interval, &r.Repetitions)
+	if err == sql.ErrNoRows {
+		if score >= ReviewThreshold {
+			return nil
+		}
+		r.EaseFactor = 2.5
+	} else if err != nil {
+		return err
+	}
+
+	r.schedule(score, time.Now())
+
+	_, err = db.conn.Exec(`
+		INSERT INTO reviews (discord_id, question_id, ease_factor, interval_days, repetitions, due_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (discord_id, question_id) DO UPDATE SET
+			ease_factor = excluded.ease_factor,
+			interval_days = excluded.interval_days,
+			repetitions = excluded.repetitions,
+			due_at = excluded.due_at
+	`, r.DiscordID, r.QuestionID, r.EaseFactor, r.Interval, r.Repetitions, r.DueAt.UTC().Format(timeLayout))
+	return err
+}
+
+// GetNextDueReview returns the most overdue review for a user among the
+// questions asked in a guild, or nil if none are due
+func (db *DB) GetNextDueReview(discordID, guildID string) (*Review, error) {
+	r := &Review{DiscordID: discordID}
+	row := db.conn.QueryRow(`
+		SELECT r.question_id, r.ease_factor, r.interval_days, r.repetitions, r.due_at
+		FROM reviews AS r
+		JOIN questions AS q ON q.id = r.question_id
+		WHERE r.discord_id = ? AND q.guild_id = ? AND r.due_at <= ?
+		ORDER BY r.due_at
+		LIMIT 1
+	`, discordID, guildID, time.Now().UTC().Format(timeLayout))
+	err := row.Scan(&r.QuestionID, &r.EaseFactor, &r.Interval, &r.Repetitions, &r.DueAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// CountDueReviews returns how many reviews are currently due for a user among
+// the questions asked in a guild
+func (db *DB) CountDueReviews(discordID, guildID string) (int, error) {
+	var count int
+	row := db.conn.QueryRow(`
+		SELECT COUNT(*) FROM reviews AS r
+		JOIN questions AS q ON q.id = r.question_id
+		WHERE r.discord_id = ? AND q.guild_id = ? AND r.due_at <= ?
+	`, discordID, guildID, time.Now().UTC().Format(timeLayout))
+	err := row.Scan(&count)
+	return count, err
+}
+
+// schedule applies the SM-2 algorithm, mapping a 0-100 score to a 0-5 quality grade
+func (r *Review) schedule(score int, now time.Time) {
+	quality := score / 20
+	if quality > 5 {
+		quality = 5
+	}
+	if quality < 0 {
+		quality = 0
+	}
+
+	if quality < 3 {
+		r.Repetitions = 0
+		r.Interval = 1
+	} else {
+		r.Repetitions++
+		switch r.Repetitions {
+		case 1:
+			r.Interval = 1
+		case 2:
+			r.Interval = 6
+		default:
+			r.Interval = int(math.Round(float64(r.Interval) * r.EaseFactor))
+		}
+	}
+
+	q := float64(5 - quality)
+	r.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if r.EaseFactor < 1.3 {
+		r.EaseFactor = 1.3
+	}
+
+	r.DueAt = now.AddDate(0, 0, r.Interval)
+}
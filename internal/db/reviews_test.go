@@ -0,0 +1,102 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordReview(t *testing.T) {
+	db := newTestDB(t)
+
+	qGood, _ := db.SaveQuestion("", "良い問題", "beginner", "テスト", DirectionJaEn)
+	qBad, _ := db.SaveQuestion("", "難しい問題", "beginner", "テスト", DirectionJaEn)
+
+	// High scores should not enter the review queue
+	if err := db.RecordReview("12345", qGood, 95); err != nil {
+		t.Fatalf("Failed to record review: %v", err)
+	}
+	count, err := db.CountDueReviews("12345", "")
+	if err != nil {
+		t.Fatalf("Failed to count reviews: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected 0 due reviews, got %d", count)
+	}
+
+	// Low scores should be scheduled for tomorrow
+	if err := db.RecordReview("12345", qBad, 30); err != nil {
+		t.Fatalf("Failed to record review: %v", err)
+	}
+	review, err := db.GetNextDueReview("12345", "")
+	if err != nil {
+		t.Fatalf("Failed to get review: %v", err)
+	}
+	if review != nil {
+		t.Errorf("Expected no review due yet, got question %d", review.QuestionID)
+	}
+
+	// Make it due and check it comes back
+	_, err = db.conn.Exec("UPDATE reviews SET due_at = ? WHERE question_id = ?",
+		time.Now().Add(-time.Hour).UTC().Format(timeLayout), qBad)
+	if err != nil {
+		t.Fatalf("Failed to update due date: %v", err)
+	}
+	review, err = db.GetNextDueReview("12345", "")
+	if err != nil {
+		t.Fatalf("Failed to get review: %v", err)
+	}
+	if review == nil || review.QuestionID != qBad {
+		t.Fatalf("Expected review for question %d, got %+v", qBad, review)
+	}
+	if review.Interval != 1 {
+		t.Errorf("Expected interval 1, got %d", review.Interval)
+	}
+
+	// Reviews are kept to the guild the question was asked in
+	if other, _ := db.GetNextDueReview("12345", "guild-2"); other != nil {
+		t.Errorf("Expected no review due in another guild, got question %d", other.QuestionID)
+	}
+	if count, _ := db.CountDueReviews("12345", "guild-2"); count != 0 {
+		t.Errorf("Expected no reviews counted in another guild, got %d", count)
+	}
+
+	// A good answer on review should push it further out
+	if err := db.RecordReview("12345", qBad, 90); err != nil {
+		t.Fatalf("Failed to record review: %v", err)
+	}
+	review, _ = db.GetNextDueReview("12345", "")
+	if review != nil {
+		t.Errorf("Expected review to be rescheduled, got question %d", review.QuestionID)
+	}
+}
+
+func TestReviewSchedule(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := &Review{EaseFactor: 2.5}
+
+	r.schedule(100, now)
+	if r.Repetitions != 1 || r.Interval != 1 {
+		t.Errorf("After first success expected reps 1 interval 1, got %d %d", r.Repetitions, r.Interval)
+	}
+
+	r.schedule(100, now)
+	if r.Repetitions != 2 || r.Interval != 6 {
+		t.Errorf("After second success expected reps 2 interval 6, got %d %d", r.Repetitions, r.Interval)
+	}
+
+	r.schedule(100, now)
+	if r.Interval <= 6 {
+		t.Errorf("Expected interval to grow beyond 6, got %d", r.Interval)
+	}
+	if !r.DueAt.Equal(now.AddDate(0, 0, r.Interval)) {
+		t.Errorf("Expected due date %v, got %v", now.AddDate(0, 0, r.Interval), r.DueAt)
+	}
+
+	r.schedule(20, now)
+	if r.Repetitions != 0 || r.Interval != 1 {
+		t.Errorf("After failure expected reps 0 interval 1, got %d %d", r.Repetitions, r.Interval)
+	}
+	if r.EaseFactor < 1.3 {
+		t.Errorf("Ease factor should not drop below 1.3, got %f", r.EaseFactor)
+	}
+}
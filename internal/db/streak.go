@@ -0,0 +1,64 @@
+package db
+
+import (
+	"sort"
+	"time"
+)
+
+// StreakMilestones are the streak lengths (in days) worth celebrating
+var StreakMilestones = []int{7, 30, 100}
+
+// IsStreakMilestone reports whether a streak length is one of StreakMilestones
+func IsStreakMilestone(streak int) bool {
+	for _, m := range StreakMilestones {
+		if streak == m {
+			return true
+		}
+	}
+	return false
+}
+
+// computeStreaks returns the current and longest runs of consecutive days on
+// which an answer was given. Times are bucketed into days in loc. The current
+// streak stays alive until the end of the day after the last answer.
+func computeStreaks(answeredAt []time.Time, now time.Time, loc *time.Location) (current, longest int) {
+	seen := make(map[time.Time]bool)
+	var days []time.Time
+	for _, t := range answeredAt {
+		day := localDay(t, loc)
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	if len(days) == 0 {
+		return 0, 0
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	run := 0
+	for i, day := range days {
+		if i > 0 && day.Equal(days[i-1].AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	today := localDay(now, loc)
+	last := days[len(days)-1]
+	if last.Equal(today) || last.Equal(today.AddDate(0, 0, -1)) {
+		current = run
+	}
+	return current, longest
+}
+
+// localDay returns the calendar day of t in loc, normalised to midnight UTC so
+// days can be compared and stepped without DST surprises
+func localDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
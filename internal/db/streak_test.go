@@ -0,0 +1,48 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeStreaks(t *testing.T) {
+	loc := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, loc)
+	day := func(d, hour int) time.Time {
+		return time.Date(2025, 1, d, hour, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name            string
+		answeredAt      []time.Time
+		expectedCurrent int
+		expectedLongest int
+	}{
+		{"no answers", nil, 0, 0},
+		{"answered today only", []time.Time{day(10, 9)}, 1, 1},
+		{"several answers on one day", []time.Time{day(10, 9), day(10, 10), day(10, 11)}, 1, 1},
+		{"three days ending today", []time.Time{day(8, 9), day(9, 9), day(10, 9)}, 3, 3},
+		{"streak alive until end of tomorrow", []time.Time{day(8, 9), day(9, 9)}, 2, 2},
+		{"broken streak", []time.Time{day(1, 9), day(2, 9), day(3, 9), day(4, 9), day(7, 9)}, 0, 4},
+		{"unordered input", []time.Time{day(10, 9), day(8, 9), day(9, 9)}, 3, 3},
+		{
+			// 23:30 UTC on the 8th is the 9th in JST
+			name:            "bucketed by time zone",
+			answeredAt:      []time.Time{time.Date(2025, 1, 8, 23, 30, 0, 0, time.UTC), day(10, 9)},
+			expectedCurrent: 2,
+			expectedLongest: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := computeStreaks(tt.answeredAt, now, loc)
+			if current != tt.expectedCurrent {
+				t.Errorf("Expected current streak %d, got %d", tt.expectedCurrent, current)
+			}
+			if longest != tt.expectedLongest {
+				t.Errorf("Expected longest streak %d, got %d", tt.expectedLongest, longest)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Sources of vocabulary entries
+const (
+	VocabFromCorrection = "correction"
+	VocabFromKeyPhrase  = "key_phrase"
+	VocabManual         = "manual"
+)
+
+// VocabularyEntry is a word or phrase in a user's vocabulary notebook
+type VocabularyEntry struct {
+	ID         int64
+	DiscordID  string
+	Phrase     string
+	Meaning    string // Japanese meaning, may be empty
+	Note       string // explanation from the correction it came from, may be empty
+	Source     string
+	QuestionID int64 // question it came from, 0 for manual entries
+	CreatedAt  time.Time
+}
+
+// KeyPhrase is a phrase worth memorising from a question's model answer
+type KeyPhrase struct {
+	Phrase  string
+	Meaning string
+}
+
+// AddVocabulary adds an entry to a user's notebook. Phrases are unique per
+// user regardless of case; it reports false if the phrase was already there.
+func (db *DB) AddVocabulary(e *VocabularyEntry) (bool, error) {
+	var questionID any
+	if e.QuestionID != 0 {
+		questionID = e.QuestionID
+	}
+	result, err := db.conn.Exec(
+		"INSERT INTO vocabulary (discord_id, phrase, meaning, note, source, question_id) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (discord_id, phrase) DO NOTHING",
+		e.DiscordID, e.Phrase, e.Meaning, e.Note, e.Source, questionID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// RemoveVocabulary removes a phrase and its flashcard history from a user's
+// notebook, reporting false if it was not there
+func (db *DB) RemoveVocabulary(discordID, phrase string) (bool, error) {
+	_, err := db.conn.Exec(
+		"DELETE FROM flashcard_reviews WHERE vocabulary_id IN (SELECT id FROM vocabulary WHERE discord_id = ? AND phrase = ?)",
+		discordID, phrase,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := db.conn.Exec("DELETE FROM vocabulary WHERE discord_id = ? AND phrase = ?", discordID, phrase)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// FindVocabulary gets the entry of a user's notebook for a phrase regardless
+// of case, returning nil if it is not there
+func (db *DB) FindVocabulary(discordID, phrase string) (*VocabularyEntry, error) {
+	e := &VocabularyEntry{DiscordID: discordID}
+	row := db.conn.QueryRow(
+		"SELECT id, phrase, meaning, note, source, COALESCE(question_id, 0), created_at FROM vocabulary WHERE discord_id = ? AND phrase = ?",
+		discordID, phrase,
+	)
+	err := row.Scan(&e.ID, &e.Phrase, &e.Meaning, &e.Note, &e.Source, &e.QuestionID, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// GetVocabulary returns the entries of a user's notebook, newest first
+func (db *DB) GetVocabulary(discordID string) ([]*VocabularyEntry, error) {
+	rows, err := db.conn.Query(
+		"SELECT id, phrase, meaning, note, source, COALESCE(question_id, 0), created_at FROM vocabulary WHERE discord_id = ? ORDER BY id DESC",
+		discordID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*VocabularyEntry
+	for rows.Next() {
+		e := &VocabularyEntry{DiscordID: discordID}
+		if err := rows.Scan(&e.ID, &e.Phrase, &e.Meaning, &e.Note, &e.Source, &e.QuestionID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetRandomVocabulary picks a random entry with a meaning from a user's
+// notebook, returning nil if there is none
+func (db *DB) GetRandomVocabulary(discordID string) (*VocabularyEntry, error) {
+	e := &VocabularyEntry{DiscordID: discordID}
+	row := db.conn.QueryRow(
+		"SELECT id, phrase, meaning, note, source, COALESCE(question_id, 0), created_at FROM vocabulary WHERE discord_id = ? AND meaning != '' ORDER BY RANDOM() LIMIT 1",
+		discordID,
+	)
+	err := row.Scan(&e.ID, &e.Phrase, &e.Meaning, &e.Note, &e.Source, &e.QuestionID, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// SaveKeyPhrases caches the key phrases of a question's model answer unless
+// some are cached already
+func (db *DB) SaveKeyPhrases(questionID int64, phrases []KeyPhrase) error {
+	var cached int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM key_phrases WHERE question_id = ?", questionID).Scan(&cached); err != nil {
+		return err
+	}
+	if cached > 0 {
+		return nil
+	}
+
+	for _, k := range phrases {
+		_, err := db.conn.Exec(
+			"INSERT OR IGNORE INTO key_phrases (question_id, phrase, meaning) VALUES (?, ?, ?)",
+			questionID, k.Phrase, k.Meaning,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetKeyPhrases returns the cached key phrases of a question
+func (db *DB) GetKeyPhrases(questionID int64) ([]KeyPhrase, error) {
+	rows, err := db.conn.Query("SELECT phrase, meaning FROM key_phrases WHERE question_id = ? ORDER BY rowid", questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var phrases []KeyPhrase
+	for rows.Next() {
+		var k KeyPhrase
+		if err := rows.Scan(&k.Phrase, &k.Meaning); err != nil {
+			return nil, err
+		}
+		phrases = append(phrases, k)
+	}
+	return phrases, rows.Err()
+}
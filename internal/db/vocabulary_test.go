@@ -0,0 +1,69 @@
+package db
+
+import "testing"
+
+func TestVocabulary(t *testing.T) {
+	db := newTestDB(t)
+
+	qID, _ := db.SaveQuestion("guild-1", "問題", "beginner", "テスト", DirectionJaEn)
+
+	added, err := db.AddVocabulary(&VocabularyEntry{DiscordID: "12345", Phrase: "every morning", Meaning: "毎朝", Source: VocabFromKeyPhrase, QuestionID: qID})
+	if err != nil || !added {
+		t.Fatalf("Failed to add vocabulary: %v", err)
+	}
+	db.AddVocabulary(&VocabularyEntry{DiscordID: "12345", Phrase: "look forward to", Note: "toの後は動名詞", Source: VocabFromCorrection, QuestionID: qID})
+	db.AddVocabulary(&VocabularyEntry{DiscordID: "67890", Phrase: "every morning", Source: VocabManual})
+
+	// Phrases are unique per user regardless of case
+	if added, _ := db.AddVocabulary(&VocabularyEntry{DiscordID: "12345", Phrase: "Every Morning", Source: VocabManual}); added {
+		t.Error("Expected a repeated phrase not to be added")
+	}
+
+	entries, err := db.GetVocabulary("12345")
+	if err != nil {
+		t.Fatalf("Failed to get vocabulary: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Phrase != "look forward to" || entries[1].Meaning != "毎朝" || entries[1].QuestionID != qID {
+		t.Errorf("Unexpected notebook: %+v %+v", entries[0], entries[1])
+	}
+
+	// Only entries with a meaning can be quizzed
+	e, err := db.GetRandomVocabulary("12345")
+	if err != nil || e == nil || e.Phrase != "every morning" {
+		t.Errorf("Expected the entry with a meaning, got %+v (%v)", e, err)
+	}
+	if e, _ := db.GetRandomVocabulary("67890"); e != nil {
+		t.Errorf("Expected no quizzable entry, got %+v", e)
+	}
+
+	removed, err := db.RemoveVocabulary("12345", "EVERY MORNING")
+	if err != nil || !removed {
+		t.Fatalf("Failed to remove vocabulary: %v", err)
+	}
+	if removed, _ := db.RemoveVocabulary("12345", "every morning"); removed {
+		t.Error("Expected nothing left to remove")
+	}
+	if entries, _ := db.GetVocabulary("67890"); len(entries) != 1 {
+		t.Errorf("Expected other users' notebooks untouched, got %d entries", len(entries))
+	}
+}
+
+func TestKeyPhrases(t *testing.T) {
+	db := newTestDB(t)
+
+	qID, _ := db.SaveQuestion("guild-1", "問題", "beginner", "テスト", DirectionJaEn)
+
+	if err := db.SaveKeyPhrases(qID, []KeyPhrase{{"every morning", "毎朝"}, {"drink", "飲む"}}); err != nil {
+		t.Fatalf("Failed to save key phrases: %v", err)
+	}
+	// Later evaluations do not replace the cached phrases
+	db.SaveKeyPhrases(qID, []KeyPhrase{{"coffee", "コーヒー"}})
+
+	phrases, err := db.GetKeyPhrases(qID)
+	if err != nil {
+		t.Fatalf("Failed to get key phrases: %v", err)
+	}
+	if len(phrases) != 2 || phrases[0].Phrase != "every morning" || phrases[1].Meaning != "飲む" {
+		t.Errorf("Unexpected key phrases: %+v", phrases)
+	}
+}
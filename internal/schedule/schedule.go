@@ -0,0 +1,130 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec is a parsed delivery schedule such as "weekdays 07:30,21:00"
+type Spec struct {
+	days  [7]bool
+	times []int // minutes since midnight, sorted
+}
+
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	"日": time.Sunday, "月": time.Monday, "火": time.Tuesday, "水": time.Wednesday,
+	"木": time.Thursday, "金": time.Friday, "土": time.Saturday,
+}
+
+var dayAliases = map[string][]time.Weekday{
+	"daily":    {time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+	"毎日":       {time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"平日":       {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends": {time.Saturday, time.Sunday},
+	"土日":       {time.Saturday, time.Sunday},
+}
+
+// Parse parses a schedule of the form "[days] HH:MM[,HH:MM...]".
+// Days may be daily, weekdays, weekends, or a comma-separated list or range
+// of day names (e.g. "mon,wed,fri" or "mon-fri"). Days default to daily.
+func Parse(s string) (*Spec, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("invalid schedule %q: expected \"[days] HH:MM[,HH:MM...]\"", s)
+	}
+
+	spec := &Spec{}
+	timeField := fields[len(fields)-1]
+	if len(fields) == 2 {
+		if err := spec.parseDays(fields[0]); err != nil {
+			return nil, err
+		}
+	} else {
+		for i := range spec.days {
+			spec.days[i] = true
+		}
+	}
+
+	for _, t := range strings.Split(timeField, ",") {
+		minutes, err := parseTime(t)
+		if err != nil {
+			return nil, err
+		}
+		spec.times = append(spec.times, minutes)
+	}
+	sort.Ints(spec.times)
+
+	return spec, nil
+}
+
+// parseDays parses the day part of a schedule
+func (sp *Spec) parseDays(field string) error {
+	if days, ok := dayAliases[field]; ok {
+		for _, d := range days {
+			sp.days[d] = true
+		}
+		return nil
+	}
+
+	for _, part := range strings.Split(field, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		start, ok := dayNames[from]
+		if !ok {
+			return fmt.Errorf("invalid day %q", from)
+		}
+		end := start
+		if isRange {
+			if end, ok = dayNames[to]; !ok {
+				return fmt.Errorf("invalid day %q", to)
+			}
+		}
+		for d := start; ; d = (d + 1) % 7 {
+			sp.days[d] = true
+			if d == end {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseTime parses "HH:MM" into minutes since midnight
+func parseTime(s string) (int, error) {
+	h, m, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", s)
+	}
+	hour, err := strconv.Atoi(h)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("invalid hour in %q", s)
+	}
+	minute, err := strconv.Atoi(m)
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid minute in %q", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// Next returns the first fire time strictly after the given time, evaluated in loc
+func (sp *Spec) Next(after time.Time, loc *time.Location) time.Time {
+	local := after.In(loc)
+	for offset := 0; offset <= 7; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+		if !sp.days[day.Weekday()] {
+			continue
+		}
+		for _, minutes := range sp.times {
+			candidate := time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, loc)
+			if candidate.After(after) {
+				return candidate
+			}
+		}
+	}
+	return time.Time{}
+}
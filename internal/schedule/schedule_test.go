@@ -0,0 +1,80 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"07:30", false},
+		{"weekdays 07:30,21:00", false},
+		{"mon,wed,fri 12:00", false},
+		{"mon-fri 8:05", false},
+		{"平日 07:30", false},
+		{"", true},
+		{"weekdays", true},
+		{"someday 07:30", true},
+		{"25:00", true},
+		{"07:60", true},
+		{"daily 07:30 extra", true},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q): expected error %v, got %v", tt.input, tt.wantErr, err)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	spec, err := Parse("weekdays 07:30,21:00")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		after    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "later the same morning",
+			after:    time.Date(2025, 1, 6, 6, 0, 0, 0, tokyo), // Monday
+			expected: time.Date(2025, 1, 6, 7, 30, 0, 0, tokyo),
+		},
+		{
+			name:     "evening slot",
+			after:    time.Date(2025, 1, 6, 7, 30, 0, 0, tokyo),
+			expected: time.Date(2025, 1, 6, 21, 0, 0, 0, tokyo),
+		},
+		{
+			name:     "skips the weekend",
+			after:    time.Date(2025, 1, 10, 22, 0, 0, 0, tokyo), // Friday
+			expected: time.Date(2025, 1, 13, 7, 30, 0, 0, tokyo),
+		},
+		{
+			name:     "evaluated in the given zone",
+			after:    time.Date(2025, 1, 5, 22, 0, 0, 0, time.UTC), // Monday 07:00 in Tokyo
+			expected: time.Date(2025, 1, 6, 7, 30, 0, 0, tokyo),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := spec.Next(tt.after, tokyo)
+			if !got.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}